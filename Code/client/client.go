@@ -2,61 +2,309 @@ package client
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"google.golang.org/grpc/credentials/insecure"
-	"log"
+	"google.golang.org/grpc/metadata"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"flaco/grpc_and_go/config"
 	"flaco/grpc_and_go/flaco_grpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// DeviceOperation represents a device operation with its type and success status
+// DeviceOperation represents a device operation with its type, success status and optional time
 type DeviceOperation struct {
-	Type         string `json:"type"`          // Type of the operation
-	HasSucceeded bool   `json:"has_succeeded"` // Success status of the operation
+	Type         string     `json:"type"`                // Type of the operation
+	HasSucceeded bool       `json:"has_succeeded"`       // Success status of the operation
+	Timestamp    *time.Time `json:"timestamp,omitempty"` // Time the operation happened (RFC 3339, optional)
 }
 
-// DeviceData represents data for a device including its name and operations performed
+// DeviceData represents data for a device including its name, the day of its operations and the operations performed
 type DeviceData struct {
-	DeviceName string            `json:"device_name"` // Name of the device
-	Operations []DeviceOperation `json:"operations"`  // List of operations performed on the device
+	DeviceName string            `json:"device_name"`   // Name of the device
+	Day        string            `json:"day,omitempty"` // Day the operations happened (defaults to the name of the file)
+	Operations []DeviceOperation `json:"operations"`    // List of operations performed on the device
 }
 
-// NewClient initializes a new gRPC client, reads device data, converts it, and sends it to the server
-func NewClient(addr string) {
-	// Get device data from the specified directory
-	devices, err := GetDeviceData("./donnees/")
+// Dial connects to the configured gRPC server, with TLS and a bearer token when configured, and waits until it is ready, for at most the configured ready timeout,
+// so that a client started along with the server does not fail while the server starts
+func Dial(ctx context.Context, cfg config.ClientConfig) (*grpc.ClientConn, error) {
+	creds, err := transportCredentials(cfg)
 	if err != nil {
-		log.Fatalf("Error getting device data: %v", err)
+		return nil, err
+	}
+	options := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	token, err := tokenCredentials(cfg)
+	if err != nil {
+		return nil, err
 	}
+	if token != nil {
+		options = append(options, grpc.WithPerRPCCredentials(token))
+	}
+	conn, err := grpc.Dial(cfg.ServerAddr, options...)
+	if err != nil {
+		return nil, fmt.Errorf("dialing server: %w", err)
+	}
+	if err = WaitForServer(ctx, conn, cfg.ReadyTimeout); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
 
-	// Establish a connection to the gRPC server using insecure credentials
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// WaitForServer waits until the server of conn is reachable and reports itself as serving through the gRPC health
+// service, for at most timeout. A server without health service is considered ready as soon as it answers.
+func WaitForServer(ctx context.Context, conn *grpc.ClientConn, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// WaitForReady keeps the call pending while the server cannot be reached, instead of failing at once
+	resp, err := healthgrpc.NewHealthClient(conn).Check(ctx, &healthgrpc.HealthCheckRequest{}, grpc.WaitForReady(true))
+	if status.Code(err) == codes.Unimplemented {
+		return nil
+	}
 	if err != nil {
-		log.Fatalf("Fail to dial: %v", err) // Log and terminate if connection fails
+		return fmt.Errorf("server %s not ready after %v: %w", conn.Target(), timeout, err)
+	}
+	if resp.Status != healthgrpc.HealthCheckResponse_SERVING {
+		return fmt.Errorf("server %s not serving: %v", conn.Target(), resp.Status)
+	}
+	return nil
+}
+
+// Upload streams the device data of a file, or of every file of a directory, to the configured gRPC server, one file
+// at a time, and prints the summary of the server. Invalid files are handled according to mode, see StreamDeviceData.
+func Upload(ctx context.Context, cfg config.ClientConfig, path string, mode ErrorMode) error {
+	// List the device data files to upload
+	paths, err := ReadDeviceDataFromFiles(path)
+	if err != nil {
+		return fmt.Errorf("listing device data files: %w", err)
+	}
+
+	conn, err := Dial(ctx, cfg)
+	if err != nil {
+		return err
 	}
 	defer conn.Close() // Ensure the connection is closed when done
 
-	// Create a new DayService client from the connection
-	client := flaco_grpc.NewDayServiceClient(conn)
+	// Invalid files are reported while streaming and, with SkipInvalidFiles, do not prevent the other files from being uploaded
+	summary, err := StreamDeviceData(ctx, flaco_grpc.NewDayServiceClient(conn), paths, mode)
+	var skipped FileErrors
+	if err != nil && !errors.As(err, &skipped) {
+		return err
+	}
+	fmt.Printf("[LOGS] => Server stored %d devices and %d operations\n", summary.Devices, summary.Operations)
+	for _, result := range summary.Results {
+		fmt.Println("[LOGS] =>", FormatDeviceResult(result))
+	}
+	return err
+}
+
+// WatchClient connects to the configured gRPC server and uploads the files of dataDir as they are created or modified,
+// until ctx is done. The uploaded files are recorded in the configured state file, so that they are not uploaded
+// again when the client is restarted.
+func WatchClient(ctx context.Context, cfg config.ClientConfig, dataDir string) error {
+	state, err := LoadUploadState(cfg.StateFile)
+	if err != nil {
+		return err
+	}
+
+	conn, err := Dial(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return NewWatcher(flaco_grpc.NewDayServiceClient(conn), dataDir, state).Run(ctx)
+}
 
-	// Convert the device data to the format expected by the gRPC service
-	var devicesConverted []*flaco_grpc.Device
-	for _, device := range devices {
-		devicesConverted = append(devicesConverted, ConvertDeviceDataToGRPCDevice(device))
+// ListAllDeviceStats returns the statistics of every device whose name contains filter, requesting every page
+func ListAllDeviceStats(ctx context.Context, client flaco_grpc.DayServiceClient, filter string) ([]*flaco_grpc.DeviceStat, error) {
+	var stats []*flaco_grpc.DeviceStat
+	req := &flaco_grpc.ListDeviceStatsRequest{NameFilter: filter}
+	for {
+		page, err := client.ListDeviceStats(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, page.Stats...)
+		if page.NextPageToken == "" {
+			return stats, nil
+		}
+		req.PageToken = page.NextPageToken
 	}
+}
 
-	// Send the converted device data to the server
-	_, err = client.SendDayInfoToServer(context.Background(), &flaco_grpc.Request{Device: devicesConverted})
+// FormatDeviceResult describes in one line how the server ingested the operations of a device
+func FormatDeviceResult(result *flaco_grpc.DeviceResult) string {
+	line := fmt.Sprintf("%s: %d accepted, %d rejected", result.DeviceName, result.Accepted, result.Rejected)
+	if result.Stat != nil {
+		line += fmt.Sprintf(" (total %d, successful %d, failed %d)", result.Stat.Total, result.Stat.Successful, result.Stat.Failed)
+	}
+	if result.Error != "" {
+		line += ", error: " + result.Error
+	}
+	return line
+}
+
+// StreamDeviceData uploads the files one after the other and merges the summaries of the files the server stored.
+// Files the server had already stored are skipped by the server and left out of the returned summary.
+// Files that cannot be read are handled according to mode, as in ReadDeviceData: with SkipInvalidFiles the summary
+// of the other files is returned along with a FileErrors listing them.
+func StreamDeviceData(ctx context.Context, client flaco_grpc.DayServiceClient, paths []string, mode ErrorMode) (*flaco_grpc.IngestSummary, error) {
+	total := &flaco_grpc.IngestSummary{}
+	var skipped FileErrors
+	for _, path := range paths {
+		summary, err := StreamFile(ctx, client, path)
+		var fileErr *FileError
+		if mode == SkipInvalidFiles && errors.As(err, &fileErr) {
+			fmt.Println("[LOGS] => Invalid file skipped:", err)
+			skipped = append(skipped, fileErr)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("uploading %s: %w", path, err)
+		}
+		if summary.Replayed {
+			fmt.Printf("[LOGS] => File already uploaded, skipped by the server: %s\n", path)
+			continue
+		}
+		total.Devices += summary.Devices
+		total.Operations += summary.Operations
+		total.Results = append(total.Results, summary.Results...)
+	}
+	return total, skipped.orNil()
+}
+
+// StreamFile streams the devices of a single file to the server, identified by the hash of its day and content so that
+// uploading the same file again does not store its data twice. The file is read twice, one device at a time: once to
+// hash it and reject it before anything is sent if it is invalid, since the server stores the devices as they arrive,
+// then to send each device as soon as it is decoded.
+func StreamFile(ctx context.Context, client flaco_grpc.DayServiceClient, path string) (*flaco_grpc.IngestSummary, error) {
+	key, _, err := checkDeviceFile(path)
 	if err != nil {
-		fmt.Println("[LOGS] => Error sending data to server:", err) // Print error if the request fails
+		return nil, err
 	}
+	return streamOperations(ctx, client, path, key, nil, nil)
 }
 
-// ReadDeviceDataFromFiles reads all file paths in the given directory
+// streamOperations streams the devices of the file at path to the server with the idempotency key key, keeping the
+// operations whose rank among the ones of their device and day in the file is at least from and below to. A nil from
+// keeps every operation from the first one and a nil to every operation up to the last one. The devices of from
+// without operations left are not sent, nor the ones missing from a non-nil to.
+func streamOperations(ctx context.Context, client flaco_grpc.DayServiceClient, path string, key string, from, to OperationCounts) (*flaco_grpc.IngestSummary, error) {
+	file, err := OpenDeviceFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Cancelling the stream keeps the server from recording the file as uploaded if it cannot be read to the end
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(ctx, flaco_grpc.IdempotencyKeyMetadata, key))
+	defer cancel()
+	stream, err := client.StreamDayInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	read := OperationCounts{} // Operations read so far, by device and day
+	for {
+		device, err := file.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err // The file was changed since it was checked
+		}
+		first, _ := read.Get(device.DeviceName, device.Day)
+		read.add(device.DeviceName, device.Day, int64(len(device.Operations)))
+		if to != nil {
+			last, ok := to.Get(device.DeviceName, device.Day)
+			if !ok {
+				continue
+			}
+			device.Operations = device.Operations[:min(max(last-first, 0), int64(len(device.Operations)))]
+		}
+		if shipped, ok := from.Get(device.DeviceName, device.Day); ok {
+			skipped := min(max(shipped-first, 0), int64(len(device.Operations)))
+			if skipped == int64(len(device.Operations)) {
+				continue
+			}
+			device.Operations = device.Operations[skipped:]
+		}
+		// Convert the device data to the format expected by the gRPC service and send it right away
+		if err = stream.Send(ConvertDeviceDataToGRPCDevice(device)); err != nil {
+			// The server closes the stream early when the file was already uploaded or when it fails,
+			// CloseAndRecv returns its summary or the real cause of the failure
+			return stream.CloseAndRecv()
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// checkDeviceFile decodes every device of the file at path without keeping them and returns the idempotency key of
+// the file, hashing the day it is named after then its raw content in the same pass, along with its operations counted
+// by device and day. The day is part of the key since it is the one of the devices without their own day: two day files
+// with the same content hold different data.
+func checkDeviceFile(path string) (string, OperationCounts, error) {
+	raw, err := os.Open(path)
+	if err != nil {
+		return "", nil, &FileError{Path: path, Err: err}
+	}
+	hash := sha256.New()
+	hash.Write([]byte(DayFromPath(path) + "\x00"))
+	content, err := decompressData(path, io.TeeReader(raw, hash), raw)
+	if err != nil {
+		raw.Close()
+		return "", nil, &FileError{Path: path, Err: err}
+	}
+	file, err := newDeviceFile(path, content)
+	if err != nil {
+		content.Close()
+		return "", nil, err
+	}
+	defer file.Close()
+
+	operations := OperationCounts{}
+	for {
+		device, err := file.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		operations.add(device.DeviceName, device.Day, int64(len(device.Operations)))
+	}
+	// The decoder may stop before the end of the file, the rest is hashed too
+	if _, err = io.Copy(hash, raw); err != nil {
+		return "", nil, &FileError{Path: path, Err: err}
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), operations, nil
+}
+
+// deltaKey returns the idempotency key of the upload of the operations of a file beyond shipped, key being the one of
+// the whole file, which covers its day and content, so that the same content uploaded from another starting point is not taken for a replay
+func deltaKey(key string, shipped OperationCounts) string {
+	if len(shipped) == 0 {
+		return key // The whole file, as uploaded by StreamFile
+	}
+	counts, _ := json.Marshal(shipped) // The keys of the maps are sorted
+	hash := sha256.Sum256(append([]byte(key+"\x00"), counts...))
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
+
+// ReadDeviceDataFromFiles reads all file paths in the given directory, leaving out the files no Reader is registered for
 func ReadDeviceDataFromFiles(pathString string) ([]string, error) {
 	var paths []string
 	// Walk through the directory and collect all file paths
@@ -65,7 +313,9 @@ func ReadDeviceDataFromFiles(pathString string) ([]string, error) {
 			fmt.Println("[LOGS] => Error during file walk:", err)
 			return err // Return the error encountered during file traversal
 		}
-		if !info.IsDir() {
+		if !info.IsDir() && readerFor(path) == nil {
+			fmt.Printf("[LOGS] => Unsupported file ignored: %s\n", path)
+		} else if !info.IsDir() {
 			fmt.Printf("[LOGS] => File found: %s\n", path)
 			paths = append(paths, path) // Add file path to the list if it's not a directory
 		}
@@ -77,28 +327,109 @@ func ReadDeviceDataFromFiles(pathString string) ([]string, error) {
 	return paths, nil
 }
 
-// GetDeviceData reads device data from a specified directory and unmarshals the JSON content into a slice of DeviceData
+// GetDeviceData reads device data from a specified directory and unmarshals the JSON content into a slice of DeviceData.
+// Invalid files are skipped, see ReadDeviceData.
 func GetDeviceData(dirPath string) ([]DeviceData, error) {
+	return ReadDeviceData(dirPath, SkipInvalidFiles)
+}
+
+// ReadDeviceData reads the device data of every file of a directory. With FailFast the first invalid file stops the
+// reading and its *FileError is returned. With SkipInvalidFiles the data of the valid files is returned along with a
+// FileErrors listing the skipped files, or a nil error when every file is valid.
+func ReadDeviceData(dirPath string, mode ErrorMode) ([]DeviceData, error) {
 	paths, err := ReadDeviceDataFromFiles(dirPath)
 	if err != nil {
 		return nil, err
 	}
 
 	var devices []DeviceData
+	var skipped FileErrors
 	for _, path := range paths {
-		// Read the JSON file from the given path
-		jsonData, err := os.ReadFile(path)
+		deviceData, err := ReadDeviceDataFromFile(path)
 		if err != nil {
-			fmt.Println("[LOGS] => Error reading file:", err)
-			return nil, err
+			var fileErr *FileError
+			if mode == FailFast || !errors.As(err, &fileErr) {
+				return nil, err
+			}
+			fmt.Println("[LOGS] => Invalid file skipped:", err)
+			skipped = append(skipped, fileErr)
+			continue
 		}
-		// Unmarshal the JSON data into a slice of DeviceData
-		var deviceData []DeviceData
-		err = json.Unmarshal(jsonData, &deviceData)
 		// Append the device data to the devices slice
 		devices = append(devices, deviceData...)
 	}
-	return devices, nil
+	return devices, skipped.orNil()
+}
+
+// ReadDeviceDataFromFile reads every device of a single file, see DeviceFile
+func ReadDeviceDataFromFile(path string) ([]DeviceData, error) {
+	file, err := OpenDeviceFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return DecodeAll(file)
+}
+
+// DeviceFile decodes the devices of a data file one at a time with the Reader registered for its extension,
+// decompressing the .gz and .zst files on the fly, so that a file of any size can be read. The content is checked
+// strictly: unknown fields, values of the wrong type and devices without name are rejected with a *FileError locating them.
+type DeviceFile struct {
+	path    string
+	content io.ReadCloser
+	decoder DeviceDecoder
+}
+
+// OpenDeviceFile opens the data file at path
+func OpenDeviceFile(path string) (*DeviceFile, error) {
+	content, err := openDataFile(path)
+	if err != nil {
+		return nil, &FileError{Path: path, Err: err}
+	}
+	file, err := newDeviceFile(path, content)
+	if err != nil {
+		content.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// newDeviceFile creates the DeviceFile decoding content, the decompressed content of the file at path
+func newDeviceFile(path string, content io.ReadCloser) (*DeviceFile, error) {
+	reader := readerFor(path)
+	if reader == nil {
+		format, _ := splitExtensions(path)
+		return nil, &FileError{Path: path, Err: fmt.Errorf("unsupported file extension %q", format)}
+	}
+	return &DeviceFile{path: path, content: content, decoder: reader(content)}, nil
+}
+
+// Next returns the next device of the file, or io.EOF after the last one. The devices without their own day belong
+// to the day the file is named after.
+func (f *DeviceFile) Next() (DeviceData, error) {
+	device, err := f.decoder.Next()
+	if err == io.EOF {
+		return device, io.EOF
+	}
+	if err != nil {
+		return device, newFileError(f.path, err)
+	}
+	if device.Day == "" {
+		device.Day = DayFromPath(f.path)
+	}
+	return device, nil
+}
+
+// Close closes the file
+func (f *DeviceFile) Close() error {
+	return f.content.Close()
+}
+
+// DayFromPath returns the day a data file is named after, which is its name without extensions
+// (e.g. "journee_1" for "donnees/journee_1.json")
+func DayFromPath(path string) string {
+	day, _, _ := strings.Cut(filepath.Base(path), ".")
+	return day
 }
 
 // ConvertDeviceDataToGRPCDevice converts DeviceData to the gRPC Device type
@@ -106,14 +437,19 @@ func ConvertDeviceDataToGRPCDevice(deviceData DeviceData) *flaco_grpc.Device {
 	var operations []*flaco_grpc.Operation
 	// Convert each operation to the gRPC Operation type
 	for _, op := range deviceData.Operations {
-		operations = append(operations, &flaco_grpc.Operation{
+		operation := &flaco_grpc.Operation{
 			Type:         op.Type,
 			HasSucceeded: op.HasSucceeded,
-		})
+		}
+		if op.Timestamp != nil {
+			operation.Timestamp = timestamppb.New(*op.Timestamp)
+		}
+		operations = append(operations, operation)
 	}
 	// Return a new gRPC Device with the converted operations
 	return &flaco_grpc.Device{
 		DeviceName: deviceData.DeviceName,
+		Day:        deviceData.Day,
 		Operation:  operations,
 	}
 }
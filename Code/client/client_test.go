@@ -1,9 +1,35 @@
 package client
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flaco/grpc_and_go/config"
+	"flaco/grpc_and_go/flaco_grpc"
+	"fmt"
+	"io"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 func TestReadDeviceDataFromFiles(t *testing.T) {
@@ -111,3 +137,808 @@ func TestConvertDeviceDataToGRPCDevice(t *testing.T) {
 		t.Logf("Expected operation: {Type: \"DELETE\", HasSucceeded: false}, obtained: %+v", grpcDevice.Operation[1])
 	}
 }
+
+// streamRecorder is a fake DayService server recording the devices it receives through StreamDayInfo,
+// and skipping the streams whose idempotency key it has already seen
+type streamRecorder struct {
+	flaco_grpc.UnimplementedDayServiceServer
+	devices    []string
+	operations map[string]int64 // Operations received by device
+	keys       map[string]bool
+}
+
+func (r *streamRecorder) StreamDayInfo(stream flaco_grpc.DayService_StreamDayInfoServer) error {
+	keys := metadata.ValueFromIncomingContext(stream.Context(), flaco_grpc.IdempotencyKeyMetadata)
+	if len(keys) != 1 {
+		return status.Error(codes.InvalidArgument, "missing idempotency key")
+	}
+	if r.keys[keys[0]] {
+		return stream.SendAndClose(&flaco_grpc.IngestSummary{Replayed: true})
+	}
+	if r.keys == nil {
+		r.keys = make(map[string]bool)
+	}
+	r.keys[keys[0]] = true
+
+	summary := &flaco_grpc.IngestSummary{}
+	for {
+		device, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(summary)
+		}
+		if err != nil {
+			return err
+		}
+		r.devices = append(r.devices, device.DeviceName)
+		if r.operations == nil {
+			r.operations = make(map[string]int64)
+		}
+		r.operations[device.DeviceName] += int64(len(device.Operation))
+		summary.Devices++
+		summary.Operations += int64(len(device.Operation))
+		summary.Results = append(summary.Results, &flaco_grpc.DeviceResult{
+			DeviceName: device.DeviceName,
+			Accepted:   int64(len(device.Operation)),
+		})
+	}
+}
+
+// startFakeServer serves the given DayService implementation in memory and returns a client connected to it
+func startFakeServer(t *testing.T, srv flaco_grpc.DayServiceServer) flaco_grpc.DayServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	flaco_grpc.RegisterDayServiceServer(s, srv)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Unable to dial fake server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return flaco_grpc.NewDayServiceClient(conn)
+}
+
+func TestStreamDeviceData(t *testing.T) {
+	// Create one JSON file per day in a temporary directory
+	tempDir := t.TempDir()
+	day1 := `[{"device_name":"device1","operations":[{"type":"CREATE","has_succeeded":true},{"type":"DELETE","has_succeeded":false}]}]`
+	day2 := `[{"device_name":"device2","operations":[{"type":"UPDATE","has_succeeded":true}]},{"device_name":"device3","operations":[]}]`
+	if err := os.WriteFile(filepath.Join(tempDir, "day1.json"), []byte(day1), 0644); err != nil {
+		t.Fatalf("Unable to write JSON file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "day2.json"), []byte(day2), 0644); err != nil {
+		t.Fatalf("Unable to write JSON file: %v", err)
+	}
+	paths, err := ReadDeviceDataFromFiles(tempDir)
+	if err != nil {
+		t.Fatalf("Error reading files: %v", err)
+	}
+
+	recorder := &streamRecorder{}
+	summary, err := StreamDeviceData(context.Background(), startFakeServer(t, recorder), paths, FailFast)
+	if err != nil {
+		t.Fatalf("Error streaming device data: %v", err)
+	}
+
+	// Check that every device was streamed, in file order
+	if len(recorder.devices) != 3 || recorder.devices[0] != "device1" || recorder.devices[2] != "device3" {
+		t.Errorf("Expected devices [device1 device2 device3], obtained: %v", recorder.devices)
+	}
+	if summary.Devices != 3 || summary.Operations != 3 {
+		t.Errorf("Expected summary of 3 devices and 3 operations, obtained: %+v", summary)
+	}
+	if len(summary.Results) != 3 || summary.Results[0].Accepted != 2 {
+		t.Errorf("Expected 3 device results with 2 accepted operations for device1, obtained: %v", summary.Results)
+	}
+
+	// Uploading the same files again is skipped by the server
+	summary, err = StreamDeviceData(context.Background(), startFakeServer(t, recorder), paths, FailFast)
+	if err != nil {
+		t.Fatalf("Error streaming device data again: %v", err)
+	}
+	if summary.Devices != 0 || len(recorder.devices) != 3 {
+		t.Errorf("Expected the replayed files to be skipped, obtained: %+v with %d devices received", summary, len(recorder.devices))
+	}
+}
+
+// fileKey returns the expected idempotency key of a file named after day with the raw content
+func fileKey(day string, content []byte) string {
+	sum := sha256.Sum256(append([]byte(day+"\x00"), content...))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestCheckDeviceFileKey(t *testing.T) {
+	tempDir := t.TempDir()
+	device := `[{"device_name":"device1","operations":[]}]`
+	files := map[string]string{
+		filepath.Join("a", "journee_1.json"): device,
+		filepath.Join("b", "journee_1.json"): device,
+		"journee_2.json":                     device,
+		"journee_3.json":                     "[]",
+	}
+	keys := make(map[string]string)
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unable to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write file: %v", err)
+		}
+		key, _, err := checkDeviceFile(path)
+		if err != nil {
+			t.Fatalf("Error hashing %s: %v", name, err)
+		}
+		keys[name] = key
+	}
+
+	// The key depends on the content of the file and on the day it is named after
+	if keys[filepath.Join("a", "journee_1.json")] != keys[filepath.Join("b", "journee_1.json")] {
+		t.Errorf("Expected files of the same day with the same content to share their key, obtained: %v", keys)
+	}
+	if keys["journee_2.json"] == keys["journee_1.json"] || keys[filepath.Join("a", "journee_1.json")] == keys["journee_2.json"] {
+		t.Errorf("Expected files of different days to have different keys, obtained: %v", keys)
+	}
+	if keys["journee_3.json"] == keys["journee_2.json"] {
+		t.Errorf("Expected files with different contents to have different keys, obtained: %v", keys)
+	}
+	if key := fileKey("journee_2", []byte(device)); keys["journee_2.json"] != key {
+		t.Errorf("Expected the SHA-256 hash of the day and the file %s, obtained: %s", key, keys["journee_2.json"])
+	}
+}
+
+func TestFormatDeviceResult(t *testing.T) {
+	// A fully stored device reports its updated statistics
+	line := FormatDeviceResult(&flaco_grpc.DeviceResult{
+		DeviceName: "device1",
+		Accepted:   2,
+		Stat:       &flaco_grpc.DeviceStat{DeviceName: "device1", Total: 5, Successful: 4, Failed: 1},
+	})
+	expected := "device1: 2 accepted, 0 rejected (total 5, successful 4, failed 1)"
+	if line != expected {
+		t.Errorf("Expected line: %q, obtained: %q", expected, line)
+	}
+
+	// A failed device reports the error instead of statistics
+	line = FormatDeviceResult(&flaco_grpc.DeviceResult{DeviceName: "device2", Accepted: 1, Rejected: 2, Error: "write failed"})
+	expected = "device2: 1 accepted, 2 rejected, error: write failed"
+	if line != expected {
+		t.Errorf("Expected line: %q, obtained: %q", expected, line)
+	}
+}
+
+func TestDayFromPath(t *testing.T) {
+	cases := map[string]string{
+		"donnees/journee_1.json":   "journee_1",
+		"/data/2024-05-01.json.gz": "2024-05-01",
+		"journee_2":                "journee_2",
+	}
+	for path, expected := range cases {
+		if day := DayFromPath(path); day != expected {
+			t.Errorf("Expected day %q for %s, obtained: %q", expected, path, day)
+		}
+	}
+}
+
+func TestReadDeviceDataFromFileDays(t *testing.T) {
+	// The first device has no day and belongs to the day of the file, the second one has its own day
+	path := filepath.Join(t.TempDir(), "journee_3.json")
+	content := `[{"device_name":"device1","operations":[{"type":"CREATE","has_succeeded":true,"timestamp":"2024-05-01T10:30:00Z"}]},
+		{"device_name":"device2","day":"journee_4","operations":[{"type":"DELETE","has_succeeded":false}]}]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Unable to write JSON file: %v", err)
+	}
+
+	devices, err := ReadDeviceDataFromFile(path)
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	if len(devices) != 2 || devices[0].Day != "journee_3" || devices[1].Day != "journee_4" {
+		t.Fatalf("Expected days journee_3 and journee_4, obtained: %+v", devices)
+	}
+
+	// The day and the timestamp are carried over to the gRPC device
+	grpcDevice := ConvertDeviceDataToGRPCDevice(devices[0])
+	if grpcDevice.Day != "journee_3" {
+		t.Errorf("Expected gRPC device day journee_3, obtained: %s", grpcDevice.Day)
+	}
+	expected := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	if !grpcDevice.Operation[0].Timestamp.AsTime().Equal(expected) {
+		t.Errorf("Expected timestamp %v, obtained: %v", expected, grpcDevice.Operation[0].Timestamp.AsTime())
+	}
+	if ConvertDeviceDataToGRPCDevice(devices[1]).Operation[0].Timestamp != nil {
+		t.Error("Expected no timestamp for an operation without time")
+	}
+}
+
+func TestReadDeviceDataFromFileStrict(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+		message string
+	}{
+		{"empty", "", 1, 1, "empty file"},
+		{"not an array", `{"device_name":"device1"}`, 1, 1, "expected a JSON array"},
+		{"unknown field", "[\n  {\"device_name\":\"device1\",\"operations\":[]},\n  {\"device_name\":\"device2\",\"color\":\"red\"}\n]", 3, 3, `device 1: json: unknown field "color"`},
+		{"missing name", "[\n  {\"operations\":[{\"type\":\"CREATE\",\"has_succeeded\":true}]}\n]", 2, 3, "device 0: missing device_name"},
+		{"wrong type", `[{"device_name":"device1","operations":[{"type":"CREATE","has_succeeded":"yes"}]}]`, 1, 2, "device 0: json: cannot unmarshal string"},
+		{"syntax error", "[\n{\"device_name\":\"device1\",}\n]", 2, 26, "device 0: invalid character '}'"},
+		{"trailing data", `[] []`, 1, 4, "unexpected data after the array"},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), "journee_1.json")
+		if err := os.WriteFile(path, []byte(c.content), 0644); err != nil {
+			t.Fatalf("Unable to write JSON file: %v", err)
+		}
+
+		_, err := ReadDeviceDataFromFile(path)
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) {
+			t.Errorf("%s: expected a FileError, obtained: %v", c.name, err)
+			continue
+		}
+		if fileErr.Path != path || fileErr.Line != c.line || fileErr.Column != c.column {
+			t.Errorf("%s: expected error at %s:%d:%d, obtained: %v", c.name, path, c.line, c.column, err)
+		}
+		if !strings.Contains(fileErr.Err.Error(), c.message) {
+			t.Errorf("%s: expected error containing %q, obtained: %v", c.name, c.message, fileErr.Err)
+		}
+	}
+}
+
+func TestReadDeviceDataModes(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"journee_1.json": `[{"device_name":"device1","operations":[]}]`,
+		"journee_2.json": `[{"device_name":"device2","operations":[]},{"operations":[]}]`,
+		"journee_3.json": `[{"device_name":"device3","operations":[]}]`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write JSON file: %v", err)
+		}
+	}
+
+	// Skip and report: the valid files are read and the invalid one is listed
+	devices, err := ReadDeviceData(tempDir, SkipInvalidFiles)
+	var skipped FileErrors
+	if !errors.As(err, &skipped) || len(skipped) != 1 || filepath.Base(skipped[0].Path) != "journee_2.json" {
+		t.Errorf("Expected journee_2.json to be reported, obtained: %v", err)
+	}
+	if len(devices) != 2 {
+		t.Errorf("Expected 2 devices from the valid files, obtained: %d", len(devices))
+	}
+
+	// Fail fast: nothing is returned but the error of the invalid file
+	devices, err = ReadDeviceData(tempDir, FailFast)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || devices != nil {
+		t.Errorf("Expected a FileError and no devices, obtained: %v (%d devices)", err, len(devices))
+	}
+}
+
+func TestStreamDeviceDataSkipsInvalidFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "day1.json"), []byte(`[{"device_name":"device1","operations":[]}]`), 0644); err != nil {
+		t.Fatalf("Unable to write JSON file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "day2.json"), []byte(`[{"device_name":1}]`), 0644); err != nil {
+		t.Fatalf("Unable to write JSON file: %v", err)
+	}
+	paths, err := ReadDeviceDataFromFiles(tempDir)
+	if err != nil {
+		t.Fatalf("Error reading files: %v", err)
+	}
+
+	recorder := &streamRecorder{}
+	summary, err := StreamDeviceData(context.Background(), startFakeServer(t, recorder), paths, SkipInvalidFiles)
+	var skipped FileErrors
+	if !errors.As(err, &skipped) || len(skipped) != 1 {
+		t.Errorf("Expected the invalid file to be reported, obtained: %v", err)
+	}
+	if summary == nil || summary.Devices != 1 || len(recorder.devices) != 1 {
+		t.Errorf("Expected the valid file to be uploaded, obtained: %+v", summary)
+	}
+
+	if _, err = StreamDeviceData(context.Background(), startFakeServer(t, &streamRecorder{}), paths, FailFast); err == nil {
+		t.Error("Expected the invalid file to stop the upload")
+	}
+}
+
+func TestReadNDJSON(t *testing.T) {
+	content := "{\"device_name\":\"device1\",\"operations\":[{\"type\":\"CREATE\",\"has_succeeded\":true}]}\n\n" +
+		"{\"device_name\":\"device2\",\"day\":\"journee_2\",\"operations\":[]}\n"
+	devices, err := DecodeAll(NewNDJSONDecoder(strings.NewReader(content)))
+	if err != nil {
+		t.Fatalf("Error reading NDJSON: %v", err)
+	}
+	if len(devices) != 2 || devices[0].Operations[0].Type != "CREATE" || devices[1].Day != "journee_2" {
+		t.Errorf("Expected 2 devices, obtained: %+v", devices)
+	}
+
+	// Errors are located on their line
+	path := filepath.Join(t.TempDir(), "journee_1.ndjson")
+	content = "{\"device_name\":\"device1\",\"operations\":[]}\n{\"device_name\":\"device2\",\"size\":3}\n"
+	if err = os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Unable to write NDJSON file: %v", err)
+	}
+	_, err = ReadDeviceDataFromFile(path)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Line != 2 || fileErr.Column != 1 {
+		t.Errorf("Expected an error at line 2, column 1, obtained: %v", err)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	content := "device_name,type,has_succeeded,day,timestamp\n" +
+		"device1,CREATE,true,,2024-05-01T10:30:00Z\n" +
+		"device1,UPDATE,0,,\n" +
+		"device2,DELETE,false,journee_2,\n" +
+		"device1,CREATE,true,,\n"
+	devices, err := DecodeAll(NewCSVDecoder(strings.NewReader(content)))
+	if err != nil {
+		t.Fatalf("Error reading CSV: %v", err)
+	}
+
+	// The operations are grouped by device, in the order the devices first appear
+	if len(devices) != 2 || devices[0].DeviceName != "device1" || len(devices[0].Operations) != 3 || devices[1].Day != "journee_2" {
+		t.Fatalf("Expected device1 with 3 operations then device2, obtained: %+v", devices)
+	}
+	if op := devices[0].Operations[0]; !op.HasSucceeded || op.Timestamp == nil || op.Timestamp.Hour() != 10 {
+		t.Errorf("Expected a successful operation at 10:30, obtained: %+v", op)
+	}
+	if devices[0].Operations[1].HasSucceeded || devices[0].Operations[1].Timestamp != nil {
+		t.Errorf("Expected a failed operation without time, obtained: %+v", devices[0].Operations[1])
+	}
+
+	cases := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+		message string
+	}{
+		{"unknown column", "device_name,type,has_succeeded,color\n", 1, 32, `unknown column "color"`},
+		{"missing column", "device_name,type\n", 1, 1, `missing column "has_succeeded"`},
+		{"invalid boolean", "device_name,type,has_succeeded\ndevice1,CREATE,yes\n", 2, 16, `invalid has_succeeded "yes"`},
+		{"missing name", "device_name,type,has_succeeded\n,CREATE,true\n", 2, 1, "missing device_name"},
+		{"field count", "device_name,type,has_succeeded\ndevice1,CREATE\n", 2, 1, "wrong number of fields"},
+	}
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), "journee_1.csv")
+		if err := os.WriteFile(path, []byte(c.content), 0644); err != nil {
+			t.Fatalf("Unable to write CSV file: %v", err)
+		}
+		_, err := ReadDeviceDataFromFile(path)
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) || fileErr.Line != c.line || fileErr.Column != c.column || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%s: expected %q at %d:%d, obtained: %v", c.name, c.message, c.line, c.column, err)
+		}
+	}
+}
+
+func TestReadDeviceDataMixedDirectory(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"journee_1.json":   `[{"device_name":"device1","operations":[]}]`,
+		"journee_2.ndjson": `{"device_name":"device2","operations":[]}`,
+		"journee_3.CSV":    "device_name,type,has_succeeded\ndevice3,CREATE,true\n",
+		"notes.txt":        "not device data",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write file: %v", err)
+		}
+	}
+
+	devices, err := ReadDeviceData(tempDir, FailFast)
+	if err != nil {
+		t.Fatalf("Error reading directory: %v", err)
+	}
+	days := map[string]string{}
+	for _, device := range devices {
+		days[device.DeviceName] = device.Day
+	}
+	if len(devices) != 3 || days["device1"] != "journee_1" || days["device2"] != "journee_2" || days["device3"] != "journee_3" {
+		t.Errorf("Expected one device per file with the day of its file, obtained: %+v", devices)
+	}
+
+	// A Reader registered for another extension makes the other files readable
+	RegisterReader(".txt", func(r io.Reader) DeviceDecoder {
+		return &lineDecoder{scanner: bufio.NewScanner(r)}
+	})
+	defer delete(readers, ".txt")
+	if devices, err = ReadDeviceData(tempDir, FailFast); err != nil || len(devices) != 4 {
+		t.Errorf("Expected 4 devices with the registered Reader, obtained: %d (err: %v)", len(devices), err)
+	}
+}
+
+// lineDecoder decodes one device per line, named after the line
+type lineDecoder struct {
+	scanner *bufio.Scanner
+}
+
+func (d *lineDecoder) Next() (DeviceData, error) {
+	if !d.scanner.Scan() {
+		if err := d.scanner.Err(); err != nil {
+			return DeviceData{}, err
+		}
+		return DeviceData{}, io.EOF
+	}
+	return DeviceData{DeviceName: d.scanner.Text()}, nil
+}
+
+func TestJSONDecoderStreams(t *testing.T) {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.Write([]byte(`[{"device_name":"device1","operations":[]},`))
+		// The rest of the array is only written once the first device has been decoded
+		writer.Write([]byte(`{"device_name":"device2","operations":[]}]`))
+		writer.Close()
+	}()
+
+	decoder := NewJSONDecoder(reader)
+	for _, name := range []string{"device1", "device2"} {
+		device, err := decoder.Next()
+		if err != nil || device.DeviceName != name {
+			t.Fatalf("Expected %s, obtained: %+v (err: %v)", name, device, err)
+		}
+	}
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF after the last device, obtained: %v", err)
+	}
+}
+
+func TestStreamFileLargeFile(t *testing.T) {
+	const count = 5000
+	path := filepath.Join(t.TempDir(), "journee_1.json.gz")
+	var content strings.Builder
+	content.WriteString("[")
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			content.WriteString(",")
+		}
+		fmt.Fprintf(&content, `{"device_name":"device%d","operations":[{"type":"CREATE","has_succeeded":true}]}`, i)
+	}
+	content.WriteString("]")
+	writeCompressed(t, path, content.String())
+
+	recorder := &streamRecorder{}
+	if _, err := StreamFile(context.Background(), startFakeServer(t, recorder), path); err != nil {
+		t.Fatalf("Error streaming file: %v", err)
+	}
+	if len(recorder.devices) != count || recorder.devices[count-1] != fmt.Sprintf("device%d", count-1) {
+		t.Errorf("Expected %d devices in order, obtained: %d", count, len(recorder.devices))
+	}
+
+	// The key sent is the hash of the day and the raw, compressed, file, computed while the file is checked
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to read file: %v", err)
+	}
+	if !recorder.keys[fileKey(DayFromPath(path), raw)] {
+		t.Errorf("Expected the hash of the day and the raw file as key, obtained: %v", recorder.keys)
+	}
+}
+
+// writeCompressed writes content to path, compressed with gzip or zstd according to the extension of path
+func writeCompressed(t *testing.T, path string, content string) {
+	var buffer bytes.Buffer
+	var writer io.WriteCloser
+	var err error
+	if strings.HasSuffix(path, ".gz") {
+		writer = gzip.NewWriter(&buffer)
+	} else if writer, err = zstd.NewWriter(&buffer); err != nil {
+		t.Fatalf("Unable to create zstd writer: %v", err)
+	}
+	if _, err = writer.Write([]byte(content)); err != nil {
+		t.Fatalf("Unable to compress content: %v", err)
+	}
+	writer.Close()
+	if err = os.WriteFile(path, buffer.Bytes(), 0644); err != nil {
+		t.Fatalf("Unable to write compressed file: %v", err)
+	}
+}
+
+func TestReadCompressedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	writeCompressed(t, filepath.Join(tempDir, "journee_1.json.gz"), `[{"device_name":"device1","operations":[{"type":"CREATE","has_succeeded":true}]}]`)
+	writeCompressed(t, filepath.Join(tempDir, "journee_2.json.zst"), `[{"device_name":"device2","operations":[]}]`)
+	writeCompressed(t, filepath.Join(tempDir, "journee_3.csv.gz"), "device_name,type,has_succeeded\ndevice3,DELETE,false\n")
+	writeCompressed(t, filepath.Join(tempDir, "archive.tar.gz"), "not device data")
+
+	devices, err := ReadDeviceData(tempDir, FailFast)
+	if err != nil {
+		t.Fatalf("Error reading compressed files: %v", err)
+	}
+	days := map[string]string{}
+	for _, device := range devices {
+		days[device.DeviceName] = device.Day
+	}
+	if len(devices) != 3 || days["device1"] != "journee_1" || days["device2"] != "journee_2" || days["device3"] != "journee_3" {
+		t.Errorf("Expected one device per compressed file with the day of its file, obtained: %+v", devices)
+	}
+
+	// Errors are located in the decompressed content
+	path := filepath.Join(tempDir, "journee_4.json.zst")
+	writeCompressed(t, path, "[\n  {\"device_name\":\"device4\",\"operations\":[]},\n  {\"operations\":[]}\n]")
+	_, err = ReadDeviceDataFromFile(path)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Line != 3 || fileErr.Column != 3 {
+		t.Errorf("Expected an error at line 3, column 3, obtained: %v", err)
+	}
+
+	// A corrupted compressed file is reported as invalid
+	path = filepath.Join(tempDir, "journee_5.json.gz")
+	if err = os.WriteFile(path, []byte("not gzip"), 0644); err != nil {
+		t.Fatalf("Unable to write file: %v", err)
+	}
+	if _, err = ReadDeviceDataFromFile(path); !errors.As(err, &fileErr) {
+		t.Errorf("Expected a FileError for a corrupted file, obtained: %v", err)
+	}
+}
+
+func TestSplitExtensions(t *testing.T) {
+	cases := map[string][2]string{
+		"donnees/journee_1.json":     {".json", ""},
+		"donnees/journee_1.JSON.GZ":  {".json", ".gz"},
+		"donnees/journee_1.csv.zst":  {".csv", ".zst"},
+		"donnees/journee_1.gz":       {"", ".gz"},
+		"donnees/journee_1.json.bz2": {".bz2", ""},
+	}
+	for path, expected := range cases {
+		if format, compression := splitExtensions(path); format != expected[0] || compression != expected[1] {
+			t.Errorf("Expected %v for %s, obtained: [%s %s]", expected, path, format, compression)
+		}
+	}
+}
+
+// waitUploaded waits until the watched file dir/name is recorded in state as uploaded
+func waitUploaded(t *testing.T, state *UploadState, dir string, name string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && state.Uploaded(name, info) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected %s to be uploaded", name)
+}
+
+// runWatcher runs a Watcher of dir with short delays until the returned function is called
+func runWatcher(t *testing.T, recorder *streamRecorder, dir string, state *UploadState) (stop func()) {
+	watcher := NewWatcher(startFakeServer(t, recorder), dir, state)
+	watcher.Settle, watcher.Retry = 10*time.Millisecond, 10*time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Run(ctx) }()
+	return func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Error watching directory: %v", err)
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), "uploads.json")
+	write := func(name, content string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatalf("Unable to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write file: %v", err)
+		}
+	}
+	write("journee_1.json", `[{"device_name":"device1","operations":[]}]`)
+
+	state, err := LoadUploadState(statePath)
+	if err != nil {
+		t.Fatalf("Error loading state: %v", err)
+	}
+	recorder := &streamRecorder{}
+	stop := runWatcher(t, recorder, dir, state)
+
+	// The existing files are uploaded first, then the new, modified and nested ones
+	waitUploaded(t, state, dir, "journee_1.json")
+	write("journee_2.ndjson", `{"device_name":"device2","operations":[]}`)
+	write(filepath.Join("mai", "journee_3.json"), `[{"device_name":"device3","operations":[]}]`)
+	waitUploaded(t, state, dir, "journee_2.ndjson")
+	waitUploaded(t, state, dir, filepath.Join("mai", "journee_3.json"))
+	write("journee_1.json", `[{"device_name":"device1","operations":[{"type":"CREATE","has_succeeded":true}]}]`)
+	waitUploaded(t, state, dir, "journee_1.json")
+	stop()
+	if len(recorder.devices) != 4 {
+		t.Errorf("Expected 4 uploaded devices, obtained: %v", recorder.devices)
+	}
+
+	// A restarted watcher only uploads the files that were not recorded in the state file
+	state, err = LoadUploadState(statePath)
+	if err != nil {
+		t.Fatalf("Error loading state: %v", err)
+	}
+	recorder = &streamRecorder{}
+	stop = runWatcher(t, recorder, dir, state)
+	write("journee_4.json", `[{"device_name":"device4","operations":[]}]`)
+	waitUploaded(t, state, dir, "journee_4.json")
+	stop()
+	if len(recorder.devices) != 1 || recorder.devices[0] != "device4" {
+		t.Errorf("Expected only device4 to be uploaded after the restart, obtained: %v", recorder.devices)
+	}
+}
+
+func TestWatcherModifiedFile(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), "uploads.json")
+	// write writes journee_1.json with the given numbers of operations of each device
+	write := func(counts map[string]int) {
+		var devices []DeviceData
+		for name, count := range counts {
+			devices = append(devices, DeviceData{DeviceName: name, Operations: make([]DeviceOperation, count)})
+		}
+		data, err := json.Marshal(devices)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(dir, "journee_1.json"), data, 0644); err != nil {
+			t.Fatalf("Unable to write file: %v", err)
+		}
+	}
+	checkTotals := func(recorder *streamRecorder, expected map[string]int64) {
+		if !reflect.DeepEqual(recorder.operations, expected) {
+			t.Errorf("Expected the operations %v to be uploaded, obtained: %v", expected, recorder.operations)
+		}
+	}
+
+	write(map[string]int{"device1": 2, "device2": 1})
+	state, err := LoadUploadState(statePath)
+	if err != nil {
+		t.Fatalf("Error loading state: %v", err)
+	}
+	recorder := &streamRecorder{}
+	stop := runWatcher(t, recorder, dir, state)
+	waitUploaded(t, state, dir, "journee_1.json")
+
+	// Only the operations appended to the modified file are uploaded
+	write(map[string]int{"device1": 3, "device2": 1, "device3": 1})
+	waitUploaded(t, state, dir, "journee_1.json")
+	stop()
+	checkTotals(recorder, map[string]int64{"device1": 3, "device2": 1, "device3": 1})
+
+	// An upload interrupted before the restart is completed with its key, then the operations appended since follow
+	day := DayFromPath("journee_1.json")
+	shipped, _ := state.Progress("journee_1.json")
+	interrupted := OperationCounts{"device1": {day: 4}, "device2": {day: 1}, "device3": {day: 1}}
+	if err = state.Begin("journee_1.json", shipped, PendingUpload{Key: "interrupted", Operations: interrupted}); err != nil {
+		t.Fatalf("Error saving state: %v", err)
+	}
+	write(map[string]int{"device1": 5, "device2": 1, "device3": 1})
+	if state, err = LoadUploadState(statePath); err != nil {
+		t.Fatalf("Error loading state: %v", err)
+	}
+	recorder = &streamRecorder{}
+	stop = runWatcher(t, recorder, dir, state)
+	waitUploaded(t, state, dir, "journee_1.json")
+	stop()
+	checkTotals(recorder, map[string]int64{"device1": 2})
+	if !recorder.keys["interrupted"] || len(recorder.keys) != 2 {
+		t.Errorf("Expected the interrupted upload and the new operations to be uploaded apart, obtained: %v", recorder.keys)
+	}
+}
+
+// statsPager is a fake DayService server listing its device statistics one device per page
+type statsPager struct {
+	flaco_grpc.UnimplementedDayServiceServer
+	names []string
+}
+
+func (p *statsPager) ListDeviceStats(ctx context.Context, req *flaco_grpc.ListDeviceStatsRequest) (*flaco_grpc.ListDeviceStatsResponse, error) {
+	var matching []string
+	for _, name := range p.names {
+		if strings.Contains(name, req.NameFilter) && name > req.PageToken {
+			matching = append(matching, name)
+		}
+	}
+	if len(matching) == 0 {
+		return &flaco_grpc.ListDeviceStatsResponse{}, nil
+	}
+	resp := &flaco_grpc.ListDeviceStatsResponse{Stats: []*flaco_grpc.DeviceStat{{DeviceName: matching[0]}}}
+	if len(matching) > 1 {
+		resp.NextPageToken = matching[0]
+	}
+	return resp, nil
+}
+
+func TestListAllDeviceStats(t *testing.T) {
+	client := startFakeServer(t, &statsPager{names: []string{"device1", "device2", "sensor1"}})
+
+	stats, err := ListAllDeviceStats(context.Background(), client, "device")
+	if err != nil {
+		t.Fatalf("Error listing statistics: %v", err)
+	}
+	if len(stats) != 2 || stats[0].DeviceName != "device1" || stats[1].DeviceName != "device2" {
+		t.Errorf("Expected the statistics of device1 and device2 from every page, obtained: %v", stats)
+	}
+}
+
+func TestDialWaitsForServer(t *testing.T) {
+	// Reserve a free address, the server only starts listening on it after the client started dialing
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	cfg := config.Default().Client
+	cfg.ServerAddr, cfg.ReadyTimeout = addr, 5*time.Second
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return // The dial below fails with the timeout
+		}
+		s := grpc.NewServer()
+		healthgrpc.RegisterHealthServer(s, health.NewServer())
+		t.Cleanup(s.Stop)
+		s.Serve(listener)
+	}()
+
+	conn, err := Dial(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Expected the client to wait for the server, obtained: %v", err)
+	}
+	conn.Close()
+
+	// Without server, the client gives up after the ready timeout
+	cfg.ServerAddr, cfg.ReadyTimeout = "127.0.0.1:1", 100*time.Millisecond
+	if _, err = Dial(context.Background(), cfg); err == nil {
+		t.Error("Expected an error without server")
+	}
+}
+
+func TestTokenCredentials(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "token"), []byte("secret-token\n"), 0o600)
+
+	cfg := config.Default().Client
+	cfg.ServerAddr, cfg.TokenFile = "localhost:50051", filepath.Join(dir, "token")
+	creds, err := tokenCredentials(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, obtained: %v", err)
+	}
+	md, _ := creds.GetRequestMetadata(context.Background())
+	if md["authorization"] != "Bearer secret-token" {
+		t.Errorf("Expected the token of the file as bearer token, obtained: %v", md)
+	}
+	if creds.RequireTransportSecurity() {
+		t.Error("Expected the token to be sent over plaintext to a loopback server")
+	}
+
+	// Over plaintext, the token is not sent to a remote server
+	cfg.ServerAddr, cfg.Token = "flaco.example.com:50051", "other-token"
+	if _, err = tokenCredentials(cfg); err == nil {
+		t.Error("Expected an error sending a token to a remote server without TLS")
+	}
+	cfg.TLS = true
+	creds, err = tokenCredentials(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, obtained: %v", err)
+	}
+	md, _ = creds.GetRequestMetadata(context.Background())
+	if md["authorization"] != "Bearer other-token" || !creds.RequireTransportSecurity() {
+		t.Errorf("Expected the token of the configuration over TLS, obtained: %v", md)
+	}
+
+	cfg.Token, cfg.TokenFile = "", ""
+	if creds, err = tokenCredentials(cfg); creds != nil || err != nil {
+		t.Errorf("Expected no credentials without token, obtained: %v (err: %v)", creds, err)
+	}
+}
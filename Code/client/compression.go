@@ -0,0 +1,85 @@
+package client
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Decompressor wraps the compressed content of a file into a reader of its decompressed content
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+// decompressors holds the Decompressor of each supported compression extension
+var decompressors = map[string]Decompressor{
+	".gz":  func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	".zst": decompressZstd,
+}
+
+// decompressZstd decompresses a zstd stream on the calling goroutine, one block at a time
+func decompressZstd(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+	if err != nil {
+		return nil, err
+	}
+	return decoder.IOReadCloser(), nil
+}
+
+// splitExtensions returns the extension giving the format of a file and, when the file is compressed, the one giving
+// its compression (e.g. ".json" and ".gz" for "journee_1.json.gz")
+func splitExtensions(path string) (format, compression string) {
+	format = strings.ToLower(filepath.Ext(path))
+	if _, ok := decompressors[format]; ok {
+		compression = format
+		format = strings.ToLower(filepath.Ext(strings.TrimSuffix(path, filepath.Ext(path))))
+	}
+	return format, compression
+}
+
+// dataFile is an opened data file, decompressed while it is read when it is compressed
+type dataFile struct {
+	io.Reader
+	file         io.Closer     // Underlying file
+	decompressor io.ReadCloser // Decompressed content of the file, nil when the file is not compressed
+}
+
+// Close releases the decompressor, if any, and closes the file
+func (f *dataFile) Close() error {
+	if f.decompressor != nil {
+		f.decompressor.Close()
+	}
+	return f.file.Close()
+}
+
+// openDataFile opens the file at path and returns its content, decompressed on the fly according to its extension so
+// that the decompressed content is never held in memory as a whole
+func openDataFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := decompressData(path, file, file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return content, nil
+}
+
+// decompressData returns the content of raw, the bytes of the file at path, decompressed according to the extension
+// of path. Closing the returned content closes file.
+func decompressData(path string, raw io.Reader, file io.Closer) (io.ReadCloser, error) {
+	_, compression := splitExtensions(path)
+	if compression == "" {
+		return &dataFile{Reader: raw, file: file}, nil
+	}
+
+	decompressor, err := decompressors[compression](raw)
+	if err != nil {
+		return nil, err
+	}
+	return &dataFile{Reader: decompressor, file: file, decompressor: decompressor}, nil
+}
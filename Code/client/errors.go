@@ -0,0 +1,139 @@
+package client
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrorMode tells how the files that cannot be read are handled when reading several files
+type ErrorMode int
+
+const (
+	SkipInvalidFiles ErrorMode = iota // Invalid files are skipped and their errors reported with the data of the valid files
+	FailFast                          // Reading stops at the first invalid file
+)
+
+// FileError is an error found while reading a data file, located by its line, column and byte offset when the
+// content of the file is at fault (Line is 0 when the file could not be read at all)
+type FileError struct {
+	Path   string // Path of the file
+	Line   int    // Line of the error, starting at 1
+	Column int    // Column of the error, in bytes, starting at 1
+	Offset int64  // Byte offset of the error from the start of the file
+	Err    error  // Cause of the error
+}
+
+// Error formats the error as path:line:column: cause
+func (e *FileError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// FileErrors lists the errors of the files skipped while reading several files
+type FileErrors []*FileError
+
+// Error lists the errors of every skipped file, one per line
+func (e FileErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return fmt.Sprintf("%d invalid files:\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// orNil returns nil when no file was skipped, so that the result can be returned as an error
+func (e FileErrors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ContentError is an error a Reader found in the content of a file, located either by its byte offset or, when
+// Line is set, by its line and column
+type ContentError struct {
+	Offset int64 // Byte offset of the error from the start of the content, used when Line is 0
+	Line   int   // Line of the error, starting at 1
+	Column int   // Column of the error, in bytes, starting at 1
+	Err    error // Cause of the error
+
+	beforeValue bool // Offset may come before the value at fault, only separated from it by whitespace or commas
+}
+
+// Error returns the cause of the error, the position is reported by the FileError wrapping it
+func (e *ContentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the cause of the error
+func (e *ContentError) Unwrap() error {
+	return e.Err
+}
+
+// newFileError builds the FileError of the file at path. When err is a ContentError, the file is read again up to the
+// error to complete its position, so that the content never has to be kept in memory while it is decoded.
+func newFileError(path string, err error) *FileError {
+	fileErr := &FileError{Path: path, Err: err}
+	var contentErr *ContentError
+	if !errors.As(err, &contentErr) {
+		return fileErr
+	}
+
+	fileErr.Err = contentErr.Err
+	fileErr.Offset, fileErr.Line, fileErr.Column = contentErr.Offset, contentErr.Line, contentErr.Column
+	content, openErr := openDataFile(path)
+	if openErr != nil {
+		return fileErr // The position stays incomplete
+	}
+	defer content.Close()
+	fileErr.Offset, fileErr.Line, fileErr.Column = locate(content, contentErr)
+	return fileErr
+}
+
+// locate reads r up to the position of err and returns its offset, line and column
+func locate(r io.Reader, err *ContentError) (offset int64, line, column int) {
+	reader := bufio.NewReader(r)
+	line, column = 1, 1
+	for {
+		if err.Line > 0 && (line == err.Line && column == err.Column || line > err.Line) {
+			return offset, line, column
+		}
+		if err.Line == 0 && offset >= err.Offset && !(err.beforeValue && isSeparator(peekByte(reader))) {
+			return offset, line, column
+		}
+		b, readErr := reader.ReadByte()
+		if readErr != nil {
+			return offset, line, column // The error is at the end of the content
+		}
+		offset++
+		if b == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+}
+
+// peekByte returns the next byte of reader, or 0 at the end of the content
+func peekByte(reader *bufio.Reader) byte {
+	next, err := reader.Peek(1)
+	if err != nil {
+		return 0
+	}
+	return next[0]
+}
+
+// isSeparator tells whether b is whitespace or a separator the JSON decoder skips before a value
+func isSeparator(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n' || b == ','
+}
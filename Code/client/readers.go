@@ -0,0 +1,335 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeviceDecoder decodes the devices of a data file one at a time, so that a file never has to be held in memory
+type DeviceDecoder interface {
+	// Next returns the next device of the content, or io.EOF once every device has been returned. An error located in
+	// the content should be returned as a *ContentError, so that it is reported with its line and column.
+	Next() (DeviceData, error)
+}
+
+// Reader creates the DeviceDecoder of the content of a data file, already decompressed
+type Reader func(r io.Reader) DeviceDecoder
+
+// readers holds the Reader of each supported file extension
+var readers = map[string]Reader{
+	".json":   NewJSONDecoder,
+	".ndjson": NewNDJSONDecoder,
+	".csv":    NewCSVDecoder,
+}
+
+// RegisterReader makes the files with the given extension (e.g. ".xml") readable with reader, replacing the Reader
+// registered for it if any. The compressed files with this extension (e.g. ".xml.gz") become readable too.
+// It must be called before any file is read.
+func RegisterReader(extension string, reader Reader) {
+	readers[strings.ToLower(extension)] = reader
+}
+
+// readerFor returns the Reader registered for the format of path, or nil if the format is not supported
+func readerFor(path string) Reader {
+	format, _ := splitExtensions(path)
+	return readers[format]
+}
+
+// DecodeAll returns the remaining devices of decoder
+func DecodeAll(decoder DeviceDecoder) ([]DeviceData, error) {
+	devices := []DeviceData{}
+	for {
+		device, err := decoder.Next()
+		if err == io.EOF {
+			return devices, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+}
+
+// jsonDecoder decodes a JSON array of devices one element at a time
+type jsonDecoder struct {
+	decoder *json.Decoder
+	started bool // The opening bracket of the array has been read
+	count   int  // Number of devices decoded
+}
+
+// NewJSONDecoder creates the DeviceDecoder of a JSON array of devices, only one element of the array being held in
+// memory at a time
+func NewJSONDecoder(r io.Reader) DeviceDecoder {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return &jsonDecoder{decoder: decoder}
+}
+
+// Next decodes the next element of the array, checking that nothing follows the array once it is closed
+func (d *jsonDecoder) Next() (DeviceData, error) {
+	if !d.started {
+		token, err := d.decoder.Token()
+		if err == io.EOF {
+			return DeviceData{}, &ContentError{Err: errors.New("empty file, expected a JSON array of devices")}
+		}
+		if err != nil {
+			return DeviceData{}, valueError(err, 0, err)
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return DeviceData{}, &ContentError{Err: errors.New("expected a JSON array of devices"), beforeValue: true}
+		}
+		d.started = true
+	}
+
+	if !d.decoder.More() {
+		if _, err := d.decoder.Token(); err != nil { // Closing bracket of the array
+			return DeviceData{}, valueError(err, d.decoder.InputOffset(), err)
+		}
+		end := d.decoder.InputOffset()
+		if _, err := d.decoder.Token(); err != io.EOF {
+			return DeviceData{}, &ContentError{Offset: end, Err: errors.New("unexpected data after the array of devices"), beforeValue: true}
+		}
+		return DeviceData{}, io.EOF
+	}
+
+	start := d.decoder.InputOffset()
+	var device DeviceData
+	if err := d.decoder.Decode(&device); err != nil {
+		return DeviceData{}, valueError(err, start, fmt.Errorf("device %d: %w", d.count, err))
+	}
+	if device.DeviceName == "" {
+		return DeviceData{}, &ContentError{Offset: start, Err: fmt.Errorf("device %d: missing device_name", d.count), beforeValue: true}
+	}
+	d.count++
+	return device, nil
+}
+
+// ndjsonDecoder decodes newline-delimited JSON one line at a time
+type ndjsonDecoder struct {
+	reader *bufio.Reader
+	offset int64 // Offset of the next line
+	count  int   // Number of devices decoded
+}
+
+// NewNDJSONDecoder creates the DeviceDecoder of newline-delimited JSON, one device per line, checked as strictly as
+// a JSON array. Blank lines are ignored.
+func NewNDJSONDecoder(r io.Reader) DeviceDecoder {
+	return &ndjsonDecoder{reader: bufio.NewReader(r)}
+}
+
+// Next decodes the next line that is not blank
+func (d *ndjsonDecoder) Next() (DeviceData, error) {
+	for {
+		line, err := d.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return DeviceData{}, err
+		}
+		start := d.offset
+		d.offset += int64(len(line))
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			device, lineErr := readNDJSONLine(line)
+			if lineErr != nil {
+				lineErr.Offset += start
+				lineErr.Err = fmt.Errorf("device %d: %w", d.count, lineErr.Err)
+				return DeviceData{}, lineErr
+			}
+			d.count++
+			return device, nil
+		}
+		if err == io.EOF {
+			return DeviceData{}, io.EOF
+		}
+	}
+}
+
+// readNDJSONLine decodes the device of a single line, the offset of the returned error being relative to the line
+func readNDJSONLine(line []byte) (DeviceData, *ContentError) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.DisallowUnknownFields()
+
+	var device DeviceData
+	if err := decoder.Decode(&device); err != nil {
+		return device, valueError(err, 0, err)
+	}
+	if device.DeviceName == "" {
+		return device, &ContentError{Err: errors.New("missing device_name"), beforeValue: true}
+	}
+	end := decoder.InputOffset()
+	if _, err := decoder.Token(); err != io.EOF {
+		return device, &ContentError{Offset: end, Err: errors.New("unexpected data after the device"), beforeValue: true}
+	}
+	return device, nil
+}
+
+// valueError locates the error of decoding a JSON value starting after offset: on the character at fault for a
+// syntax error, at the beginning of the value otherwise
+func valueError(decodeErr error, offset int64, err error) *ContentError {
+	var syntaxErr *json.SyntaxError
+	if errors.As(decodeErr, &syntaxErr) && syntaxErr.Offset > 0 {
+		return &ContentError{Offset: syntaxErr.Offset - 1, Err: err} // The reported offset is the one of the character after the error
+	}
+	return &ContentError{Offset: offset, Err: err, beforeValue: true}
+}
+
+// Columns of the CSV files
+const (
+	csvDeviceName   = "device_name"   // Name of the device (required)
+	csvType         = "type"          // Type of the operation (required)
+	csvHasSucceeded = "has_succeeded" // Success status of the operation: true, false, 1 or 0 (required)
+	csvDay          = "day"           // Day the operation happened (optional)
+	csvTimestamp    = "timestamp"     // Time the operation happened, RFC 3339 (optional)
+)
+
+// csvDecoder decodes CSV, grouping the rows of each device and day wherever they appear in the file
+type csvDecoder struct {
+	reader  *csv.Reader
+	columns map[string]int // Index of each column, nil until the header is read
+	devices []DeviceData   // Devices decoded and not returned yet, nil until the rows are read
+}
+
+// csvRow is the operation of a single CSV row
+type csvRow struct {
+	name      string
+	day       string
+	operation DeviceOperation
+}
+
+// NewCSVDecoder creates the DeviceDecoder of CSV with a header line and one operation per line. The rows of a same
+// device name and day are grouped into one device, in the order the devices first appear. Since the rows of a device
+// may be anywhere in the file, e.g. in logs sorted by time, the operations of the file are read at once on the first
+// call to Next.
+func NewCSVDecoder(r io.Reader) DeviceDecoder {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	return &csvDecoder{reader: reader}
+}
+
+// Next returns the next device of the file, reading every row first
+func (d *csvDecoder) Next() (DeviceData, error) {
+	if d.devices == nil {
+		if err := d.readDevices(); err != nil {
+			return DeviceData{}, err
+		}
+	}
+	if len(d.devices) == 0 {
+		return DeviceData{}, io.EOF
+	}
+	device := d.devices[0]
+	d.devices[0] = DeviceData{} // Released once returned
+	d.devices = d.devices[1:]
+	return device, nil
+}
+
+// readDevices reads the header and every row, grouping the operations by device name and day
+func (d *csvDecoder) readDevices() error {
+	if err := d.readHeader(); err != nil {
+		return err
+	}
+	devices := []DeviceData{}
+	indexes := make(map[[2]string]int) // Index in devices of each device name and day
+	for {
+		row, err := d.readRow()
+		if err == io.EOF {
+			d.devices = devices
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		i, ok := indexes[[2]string{row.name, row.day}]
+		if !ok {
+			i = len(devices)
+			indexes[[2]string{row.name, row.day}] = i
+			devices = append(devices, DeviceData{DeviceName: row.name, Day: row.day})
+		}
+		devices[i].Operations = append(devices[i].Operations, row.operation)
+	}
+}
+
+// readHeader locates the columns, every column must be known and the required ones present
+func (d *csvDecoder) readHeader() error {
+	header, err := d.reader.Read()
+	if err == io.EOF {
+		return &ContentError{Err: errors.New("empty file, expected a CSV header")}
+	}
+	if err != nil {
+		return csvError(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		switch name {
+		case csvDeviceName, csvType, csvHasSucceeded, csvDay, csvTimestamp:
+		default:
+			return fieldError(d.reader, i, fmt.Errorf("unknown column %q", name))
+		}
+		if _, ok := columns[name]; ok {
+			return fieldError(d.reader, i, fmt.Errorf("duplicate column %q", name))
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{csvDeviceName, csvType, csvHasSucceeded} {
+		if _, ok := columns[name]; !ok {
+			return &ContentError{Err: fmt.Errorf("missing column %q", name)}
+		}
+	}
+	d.columns = columns
+	return nil
+}
+
+// readRow decodes the next row, or returns io.EOF after the last one
+func (d *csvDecoder) readRow() (*csvRow, error) {
+	record, err := d.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	row := &csvRow{name: record[d.columns[csvDeviceName]]}
+	if row.name == "" {
+		return nil, fieldError(d.reader, d.columns[csvDeviceName], errors.New("missing device_name"))
+	}
+	row.operation.Type = record[d.columns[csvType]]
+	row.operation.HasSucceeded, err = strconv.ParseBool(record[d.columns[csvHasSucceeded]])
+	if err != nil {
+		return nil, fieldError(d.reader, d.columns[csvHasSucceeded], fmt.Errorf("invalid has_succeeded %q", record[d.columns[csvHasSucceeded]]))
+	}
+	if i, ok := d.columns[csvTimestamp]; ok && record[i] != "" {
+		timestamp, err := time.Parse(time.RFC3339, record[i])
+		if err != nil {
+			return nil, fieldError(d.reader, i, fmt.Errorf("invalid timestamp %q, expected RFC 3339", record[i]))
+		}
+		row.operation.Timestamp = &timestamp
+	}
+	if i, ok := d.columns[csvDay]; ok {
+		row.day = record[i]
+	}
+	return row, nil
+}
+
+// fieldError locates err at a field of the last record read by reader
+func fieldError(reader *csv.Reader, field int, err error) error {
+	line, column := reader.FieldPos(field)
+	return &ContentError{Line: line, Column: column, Err: err}
+}
+
+// csvError locates the errors of the CSV reader, such as a line with a wrong number of fields
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &ContentError{Line: parseErr.Line, Column: parseErr.Column, Err: parseErr.Err}
+	}
+	return err
+}
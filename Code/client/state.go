@@ -0,0 +1,140 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// UploadedFile describes the version of a data file that was uploaded
+type UploadedFile struct {
+	Size       int64           `json:"size"`                 // Size of the file, in bytes
+	ModTime    time.Time       `json:"mod_time"`             // Last modification time of the file
+	Operations OperationCounts `json:"operations,omitempty"` // Operations of the file stored by the server, unknown for the files recorded by older clients
+	Pending    *PendingUpload  `json:"pending,omitempty"`    // Upload started but not acknowledged by the server yet
+}
+
+// PendingUpload describes an upload of the operations of a file that may have been interrupted, so that it is
+// completed with the same idempotency key and the server skips the devices it already stored
+type PendingUpload struct {
+	Key        string          `json:"key"`        // Idempotency key of the upload
+	Operations OperationCounts `json:"operations"` // Operations of the file stored by the server once the upload is done
+}
+
+// OperationCounts counts the operations of a data file by device name, then by day
+type OperationCounts map[string]map[string]int64
+
+// Get returns the number of operations of the device on day, and whether the file has the device on day
+func (c OperationCounts) Get(device, day string) (int64, bool) {
+	n, ok := c[device][day]
+	return n, ok
+}
+
+// add counts n more operations of the device on day
+func (c OperationCounts) add(device, day string, n int64) {
+	if c[device] == nil {
+		c[device] = make(map[string]int64)
+	}
+	c[device][day] += n
+}
+
+// exceeds tells whether c has a device and day missing from shipped, or more operations of one
+func (c OperationCounts) exceeds(shipped OperationCounts) bool {
+	for device, days := range c {
+		for day, n := range days {
+			if shippedN, ok := shipped.Get(device, day); !ok || n > shippedN {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UploadState records in a local JSON file the data files already uploaded, so that a client restarted in watch mode
+// does not upload them again. It is safe for concurrent use.
+type UploadState struct {
+	path  string
+	mu    sync.Mutex
+	files map[string]UploadedFile // Uploaded files by path relative to the data directory
+}
+
+// LoadUploadState reads the state file at path, an absent file being an empty state
+func LoadUploadState(path string) (*UploadState, error) {
+	state := &UploadState{path: path, files: make(map[string]UploadedFile)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading upload state: %w", err)
+	}
+	if err = json.Unmarshal(data, &state.files); err != nil {
+		return nil, fmt.Errorf("parsing upload state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// Uploaded tells whether the file named name, whose current information is info, was uploaded without being
+// modified since
+func (s *UploadState) Uploaded(name string, info os.FileInfo) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, ok := s.files[name]
+	return ok && file.Pending == nil && file.Size == info.Size() && file.ModTime.Equal(info.ModTime())
+}
+
+// Progress returns the operations of the file named name stored by the server, and its pending upload if any
+func (s *UploadState) Progress(name string) (OperationCounts, *PendingUpload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file := s.files[name]
+	return file.Operations, file.Pending
+}
+
+// Begin records, before it starts, the upload of the file named name, whose operations stored by the server are
+// shipped, and saves the state
+func (s *UploadState) Begin(name string, shipped OperationCounts, pending PendingUpload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file := s.files[name]
+	file.Operations, file.Pending = shipped, &pending
+	s.files[name] = file
+	return s.save()
+}
+
+// Record records the upload of the file named name, whose information was info when it was read and whose operations
+// are now all stored by the server, and saves the state
+func (s *UploadState) Record(name string, info os.FileInfo, operations OperationCounts) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = UploadedFile{Size: info.Size(), ModTime: info.ModTime(), Operations: operations}
+	return s.save()
+}
+
+// save writes the state to a temporary file renamed over the state file, so that a crash never leaves it truncated
+func (s *UploadState) save() error {
+	data, err := json.MarshalIndent(s.files, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("saving upload state: %w", err)
+	}
+	defer os.Remove(temp.Name()) // No-op once renamed
+	if _, err = temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("saving upload state: %w", err)
+	}
+	if err = temp.Close(); err != nil {
+		return fmt.Errorf("saving upload state: %w", err)
+	}
+	if err = os.Rename(temp.Name(), s.path); err != nil {
+		return fmt.Errorf("saving upload state: %w", err)
+	}
+	return nil
+}
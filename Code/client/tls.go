@@ -0,0 +1,41 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flaco/grpc_and_go/config"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// transportCredentials returns the credentials the client connects with: TLS, with a client certificate for mutual
+// TLS when one is configured, or plaintext when TLS is not configured
+func transportCredentials(cfg config.ClientConfig) (credentials.TransportCredentials, error) {
+	if !cfg.UsesTLS() {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{ServerName: cfg.ServerName, MinVersion: tls.VersionTLS12}
+	if cfg.ServerCA != "" {
+		data, err := os.ReadFile(cfg.ServerCA)
+		if err != nil {
+			return nil, fmt.Errorf("loading server CA: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.New("loading server CA: no PEM certificate found in " + cfg.ServerCA)
+		}
+	}
+	if cfg.Cert != "" {
+		certificate, err := tls.LoadX509KeyPair(cfg.Cert, cfg.Key)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"flaco/grpc_and_go/config"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// TokenCredentials sends a bearer token, an API key or a JWT, with every request of a connection
+type TokenCredentials struct {
+	Token    string // Bearer token, without the "Bearer " prefix
+	Insecure bool   // Allows sending the token over a plaintext connection
+}
+
+// GetRequestMetadata returns the authorization metadata of the requests
+func (t TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.Token}, nil
+}
+
+// RequireTransportSecurity tells whether the token may only be sent over TLS
+func (t TokenCredentials) RequireTransportSecurity() bool {
+	return !t.Insecure
+}
+
+var _ credentials.PerRPCCredentials = TokenCredentials{}
+
+// tokenCredentials returns the credentials carrying the token of the configuration, nil when none is configured.
+// Without TLS the token is only sent to a loopback server, where it cannot be sniffed.
+func tokenCredentials(cfg config.ClientConfig) (credentials.PerRPCCredentials, error) {
+	token := cfg.Token
+	if token == "" && cfg.TokenFile != "" {
+		data, err := os.ReadFile(cfg.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading token: %w", err)
+		}
+		if token = strings.TrimSpace(string(data)); token == "" {
+			return nil, errors.New("loading token: " + cfg.TokenFile + " is empty")
+		}
+	}
+	if token == "" {
+		return nil, nil
+	}
+	if !cfg.UsesTLS() && !isLoopback(cfg.ServerAddr) {
+		return nil, errors.New("a token is only sent over TLS, enable tls to connect to " + cfg.ServerAddr)
+	}
+	return TokenCredentials{Token: token, Insecure: !cfg.UsesTLS()}, nil
+}
+
+// isLoopback tells whether addr is on the local host
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
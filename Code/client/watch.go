@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"flaco/grpc_and_go/flaco_grpc"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Default delays of a Watcher
+const (
+	DefaultSettleDelay = time.Second     // Time a file must stay unchanged before it is uploaded
+	DefaultRetryDelay  = 5 * time.Second // Delay before uploading again a file whose upload failed
+)
+
+// Watcher uploads the data files of a directory and of its subdirectories as they are created or modified
+type Watcher struct {
+	Client flaco_grpc.DayServiceClient
+	Dir    string        // Directory holding the data files
+	State  *UploadState  // Files already uploaded, which are not uploaded again until they are modified
+	Settle time.Duration // Time a file must stay unchanged before it is uploaded, so that a file is not read while it is written
+	Retry  time.Duration // Delay before uploading again a file whose upload failed, e.g. because the server is down
+}
+
+// NewWatcher creates a Watcher of dir with the default delays
+func NewWatcher(client flaco_grpc.DayServiceClient, dir string, state *UploadState) *Watcher {
+	return &Watcher{Client: client, Dir: dir, State: state, Settle: DefaultSettleDelay, Retry: DefaultRetryDelay}
+}
+
+// Run uploads the files that were not uploaded yet, then the files created or modified, until ctx is done
+func (w *Watcher) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	pending := make(map[string]time.Time) // Files to upload, with the time they can be uploaded at
+	if err = w.add(watcher, w.Dir, pending); err != nil {
+		return err
+	}
+	fmt.Printf("[LOGS] => Watching %s for new day files...\n", w.Dir)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			switch {
+			case event.Has(fsnotify.Create) || event.Has(fsnotify.Write):
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// The files of a new directory may have been created before it was watched
+					if err = w.add(watcher, event.Name, pending); err != nil {
+						fmt.Println("[LOGS] => Error watching directory:", err)
+					}
+				} else if readerFor(event.Name) != nil {
+					pending[event.Name] = time.Now().Add(w.Settle) // Postponed again by every write
+				}
+			case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+				delete(pending, event.Name)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Println("[LOGS] => Error watching files:", err)
+
+		case <-timer.C:
+		}
+
+		// Upload the files that have settled, then wait for the next one
+		now := time.Now()
+		next := time.Duration(-1)
+		for path, at := range pending {
+			if at.After(now) {
+				if wait := at.Sub(now); next < 0 || wait < next {
+					next = wait
+				}
+				continue
+			}
+			delete(pending, path)
+			if err := w.upload(ctx, path); err != nil {
+				fmt.Printf("[LOGS] => Error uploading %s, retrying in %v: %v\n", path, w.Retry, err)
+				pending[path] = now.Add(w.Retry)
+				if next < 0 || w.Retry < next {
+					next = w.Retry
+				}
+			}
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if next >= 0 {
+			timer.Reset(next)
+		}
+	}
+}
+
+// add watches dir and its subdirectories and schedules the upload of the data files they already hold
+func (w *Watcher) add(watcher *fsnotify.Watcher, dir string, pending map[string]time.Time) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// The directory is watched before its files are listed, so that no file created meanwhile is missed
+			if err = watcher.Add(path); err != nil {
+				return fmt.Errorf("watching %s: %w", path, err)
+			}
+		} else if readerFor(path) != nil {
+			pending[path] = time.Now()
+		}
+		return nil
+	})
+}
+
+// upload uploads the operations of the file at path the server has not stored yet, and records them in the state.
+// A modified file is thus not counted twice: the operations of its devices are assumed to be only appended to. An
+// error is only returned when uploading the file may succeed later, an invalid file is reported and left until it is
+// modified.
+func (w *Watcher) upload(ctx context.Context, path string) error {
+	name, err := filepath.Rel(w.Dir, path)
+	if err != nil {
+		name = path
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil // Removed since it was scheduled
+	}
+	if w.State.Uploaded(name, info) {
+		return nil
+	}
+
+	// An interrupted upload is completed first with its own key, the server skipping the devices it already stored
+	shipped, pending := w.State.Progress(name)
+	if pending != nil {
+		summary, err := streamOperations(ctx, w.Client, path, pending.Key, shipped, pending.Operations)
+		if done, err := report(path, summary, err); !done {
+			return err
+		}
+		shipped = pending.Operations
+	}
+
+	key, operations, err := checkDeviceFile(path)
+	if done, err := report(path, nil, err); !done {
+		return err
+	}
+	if operations.exceeds(shipped) {
+		pending := PendingUpload{Key: deltaKey(key, shipped), Operations: operations}
+		if err = w.State.Begin(name, shipped, pending); err != nil {
+			return err
+		}
+		summary, err := streamOperations(ctx, w.Client, path, pending.Key, shipped, operations)
+		if done, err := report(path, summary, err); !done {
+			return err
+		}
+	}
+	return w.State.Record(name, info, operations)
+}
+
+// report logs the outcome of reading or uploading the file at path, summary being nil when it was only read, and
+// tells whether it succeeded. An invalid file is not an error, it is uploaded again once modified.
+func report(path string, summary *flaco_grpc.IngestSummary, err error) (bool, error) {
+	var fileErr *FileError
+	if errors.As(err, &fileErr) {
+		fmt.Println("[LOGS] => Invalid file skipped:", err)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if summary == nil {
+		return true, nil
+	}
+	if summary.Replayed {
+		fmt.Printf("[LOGS] => File already uploaded, skipped by the server: %s\n", path)
+	} else {
+		fmt.Printf("[LOGS] => %s: server stored %d devices and %d operations\n", path, summary.Devices, summary.Operations)
+		for _, result := range summary.Results {
+			fmt.Println("[LOGS] =>", FormatDeviceResult(result))
+		}
+	}
+	return true, nil
+}
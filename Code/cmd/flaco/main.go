@@ -0,0 +1,77 @@
+// Command flaco runs the gRPC server, uploads day files to it and reads back the statistics it stores.
+//
+// Usage:
+//
+//	flaco serve [flags]
+//	flaco upload [flags] [dir|file]
+//	flaco stats [flags] [device]
+//	flaco migrate [flags]
+//	flaco token [flags] name
+//
+// Every subcommand reads the configuration file given by -config or FLACO_CONFIG and the FLACO_* environment
+// variables, run "flaco <command> -h" to list its flags.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// command is a subcommand of flaco
+type command struct {
+	name  string                                      // Name of the subcommand
+	args  string                                      // Arguments following the flags, shown in the usage
+	short string                                      // One-line description
+	run   func(fs *flag.FlagSet, args []string) error // Registers the flags of the subcommand on fs, parses args and runs it
+}
+
+// commands lists the subcommands of flaco
+var commands = []command{
+	{name: "serve", short: "run the gRPC server", run: serve},
+	{name: "upload", args: "[dir|file]", short: "upload the day files of a directory, or a single day file, to the server", run: upload},
+	{name: "stats", args: "[device]", short: "print the statistics of every device, or the detailed statistics of a device", run: stats},
+	{name: "migrate", short: "move the per-device collections of previous versions into the operations collection", run: migrate},
+	{name: "token", args: "name", short: "issue a JWT for a client, signed with the configured JWT secret", run: token},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		fs := flag.NewFlagSet("flaco "+cmd.name, flag.ExitOnError)
+		fs.Usage = func() {
+			fmt.Fprintf(fs.Output(), "Usage: flaco %s [flags] %s\n\n%s\n\nFlags:\n", cmd.name, cmd.args, cmd.short)
+			fs.PrintDefaults()
+		}
+		if err := cmd.run(fs, os.Args[2:]); err != nil {
+			log.Fatalf("flaco %s: %v", cmd.name, err)
+		}
+		return
+	}
+
+	if name != "help" && name != "-h" && name != "-help" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "flaco: unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+	usage()
+}
+
+// usage lists the subcommands
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: flaco <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun \"flaco <command> -h\" to list the flags of a command.")
+}
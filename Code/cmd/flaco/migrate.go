@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flaco/grpc_and_go/config"
+	"flaco/grpc_and_go/serveur"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// migrate moves the operations stored in one MongoDB collection per device, as written by the previous versions of
+// the server, into the single operations collection
+func migrate(fs *flag.FlagSet, args []string) error {
+	dryRun := fs.Bool("dry-run", false, "only list the per-device collections that would be migrated")
+
+	// The database is configured like the server: defaults, configuration file, environment and flags
+	cfg, err := config.Load(fs, args, config.SectionServer)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	store, err := serveur.NewMongoStore(ctx, cfg.Server)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer store.Close(context.Background())
+
+	if *dryRun {
+		devices, err := store.DeviceCollections(context.Background())
+		if err != nil {
+			return fmt.Errorf("listing collections: %w", err)
+		}
+		for _, device := range devices {
+			fmt.Println(device)
+		}
+		fmt.Printf("[LOGS] => %d collections to migrate\n", len(devices))
+		return nil
+	}
+
+	println("[LOGS] => Migrating per-device collections...")
+
+	migrations, err := store.MigrateDeviceCollections(context.Background())
+	for _, migration := range migrations {
+		fmt.Printf("[LOGS] => %s: %d operations moved\n", migration.Device, migration.Moved)
+	}
+	if err != nil {
+		return fmt.Errorf("migrating: %w (run the migration again to resume it)", err)
+	}
+	fmt.Printf("[LOGS] => %d collections migrated\n", len(migrations))
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flaco/grpc_and_go/config"
+	"flaco/grpc_and_go/serveur"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout bounds the time the requests in progress are given to complete when the server is stopped
+const shutdownTimeout = 30 * time.Second
+
+// serve runs the gRPC server until it fails or it is interrupted by SIGINT or SIGTERM
+func serve(fs *flag.FlagSet, args []string) error {
+	cfg, err := config.Load(fs, args, config.SectionServer)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("[LOGS] => Server launch on %s...\n", cfg.Server.ListenAddr)
+	handle := serveur.Connect(cfg.Server)
+	if err = handle.WaitReady(ctx); err != nil {
+		return err
+	}
+	fmt.Printf("[LOGS] => Server ready on %s\n", handle.Addr())
+	if addr := handle.MetricsAddr(); addr != nil {
+		fmt.Printf("[LOGS] => Metrics served on http://%s%s\n", addr, serveur.MetricsPath)
+	}
+
+	select {
+	case <-ctx.Done():
+		println("[LOGS] => Shutting down, waiting for the requests in progress...")
+	case <-handle.Done():
+	}
+	stop() // A second signal kills the server right away
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err = handle.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	println("[LOGS] => Server stopped.")
+	return handle.Err()
+}
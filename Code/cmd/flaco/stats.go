@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flaco/grpc_and_go/client"
+	"flaco/grpc_and_go/config"
+	"flaco/grpc_and_go/flaco_grpc"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// statsTimeout bounds the time spent reading the statistics
+const statsTimeout = 30 * time.Second
+
+// stats prints the statistics of every device, or the statistics of a device by day and by operation type
+func stats(fs *flag.FlagSet, args []string) error {
+	filter := fs.String("filter", "", "only list the devices whose name contains this value")
+	cfg, err := config.Load(fs, args, config.SectionClient)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected a single device, got %v", fs.Args())
+	}
+
+	conn, err := client.Dial(context.Background(), cfg.Client)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dayService := flaco_grpc.NewDayServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	defer w.Flush()
+
+	if fs.NArg() == 0 {
+		devices, err := client.ListAllDeviceStats(ctx, dayService, *filter)
+		if err != nil {
+			return fmt.Errorf("listing statistics: %s", status.Convert(err).Message())
+		}
+		fmt.Fprintln(w, "DEVICE\tTOTAL\tSUCCESSFUL\tFAILED\t")
+		for _, device := range devices {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t\n", device.DeviceName, device.Total, device.Successful, device.Failed)
+		}
+		return nil
+	}
+
+	device, err := dayService.GetDeviceStat(ctx, &flaco_grpc.DeviceStatRequest{DeviceName: fs.Arg(0)})
+	if err != nil {
+		return fmt.Errorf("reading statistics of %s: %s", fs.Arg(0), status.Convert(err).Message())
+	}
+	fmt.Fprintln(w, "DEVICE\tTOTAL\tSUCCESSFUL\tFAILED\t")
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t\n", device.DeviceName, device.Total, device.Successful, device.Failed)
+	fmt.Fprintln(w, "\t\t\t\t")
+	fmt.Fprintln(w, "DAY\tTOTAL\tSUCCESSFUL\tFAILED\t")
+	for _, day := range device.Days {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t\n", day.Day, day.Total, day.Successful, day.Failed)
+	}
+	fmt.Fprintln(w, "\t\t\t\t")
+	fmt.Fprintln(w, "TYPE\tTOTAL\tSUCCESSFUL\tFAILED\t")
+	for _, operationType := range device.Types {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t\n", operationType.Type, operationType.Total, operationType.Successful, operationType.Failed)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"flaco/grpc_and_go/config"
+	"flaco/grpc_and_go/serveur"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// token prints a JWT naming a client, signed with the secret the server verifies the tokens with
+func token(fs *flag.FlagSet, args []string) error {
+	devices := fs.String("devices", "", "comma-separated prefixes of the devices the client may upload, any device when empty")
+	roles := fs.String("roles", "", "comma-separated roles of the client among uploader, reader and admin, required")
+	ttl := fs.Duration("ttl", 30*24*time.Hour, "validity of the token")
+
+	// The secret is configured like the server: defaults, configuration file, environment and flags
+	cfg, err := config.Load(fs, args, config.SectionServer)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected the name of the client")
+	}
+	if cfg.Server.JWTSecretFile == "" {
+		return errors.New("no JWT secret configured, set jwt_secret_file")
+	}
+	if *ttl <= 0 {
+		return errors.New("the validity of the token must be positive")
+	}
+	var clientRoles []serveur.Role
+	for _, name := range splitList(*roles) {
+		role, err := serveur.ParseRole(name)
+		if err != nil {
+			return err
+		}
+		clientRoles = append(clientRoles, role)
+	}
+	if len(clientRoles) == 0 {
+		return errors.New("expected the roles of the client, e.g. -roles uploader")
+	}
+
+	secret, err := serveur.ReadJWTSecret(cfg.Server.JWTSecretFile)
+	if err != nil {
+		return err
+	}
+	signed, err := serveur.IssueToken(secret, fs.Arg(0), splitList(*devices), clientRoles, *ttl)
+	if err != nil {
+		return fmt.Errorf("signing token: %w", err)
+	}
+	fmt.Println(signed)
+	return nil
+}
+
+// splitList returns the comma-separated values of list, without their surrounding blanks nor the empty values
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
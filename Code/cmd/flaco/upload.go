@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flaco/grpc_and_go/client"
+	"flaco/grpc_and_go/config"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// upload uploads the given day file or directory, the configured data directory by default, once or in watch mode
+func upload(fs *flag.FlagSet, args []string) error {
+	failFast := fs.Bool("fail-fast", false, "stop at the first invalid file instead of skipping it")
+	cfg, err := config.Load(fs, args, config.SectionClient)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected a single directory or file, got %v", fs.Args())
+	}
+	path := cfg.Client.DataDir
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Client.Watch {
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return fmt.Errorf("watch mode needs a directory, got %s", path)
+		}
+		// Upload the day files as they appear until interrupted
+		return client.WatchClient(ctx, cfg.Client, path)
+	}
+
+	mode := client.SkipInvalidFiles
+	if *failFast {
+		mode = client.FailFast
+	}
+	err = client.Upload(ctx, cfg.Client, path, mode)
+	var skipped client.FileErrors
+	if errors.As(err, &skipped) {
+		return fmt.Errorf("%d invalid files were not uploaded", len(skipped)) // Each one was already reported
+	}
+	return err
+}
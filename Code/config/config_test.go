@@ -0,0 +1,287 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv(EnvConfigFile, "")
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+
+	// Check that the defaults match the docker-compose setup
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Expected default configuration %+v, obtained: %+v", Default(), cfg)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	// Create a configuration file overriding three values
+	path := filepath.Join(t.TempDir(), "flaco.yaml")
+	content := "server:\n  listen_addr: \":9000\"\n  database: from_file\nclient:\n  data_dir: /data/file\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Unable to write configuration file: %v", err)
+	}
+
+	// The environment overrides the file, and the flags override the environment
+	t.Setenv(EnvConfigFile, path)
+	t.Setenv("FLACO_DATABASE", "from_env")
+	t.Setenv("FLACO_DATA_DIR", "/data/env")
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-data-dir", "/data/flag"})
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+
+	if cfg.Server.ListenAddr != ":9000" {
+		t.Errorf("Expected listen address from file: :9000, obtained: %s", cfg.Server.ListenAddr)
+	}
+	if cfg.Server.Database != "from_env" {
+		t.Errorf("Expected database from environment: from_env, obtained: %s", cfg.Server.Database)
+	}
+	if cfg.Client.DataDir != "/data/flag" {
+		t.Errorf("Expected data directory from flag: /data/flag, obtained: %s", cfg.Client.DataDir)
+	}
+	if cfg.Server.MongoURI != Default().Server.MongoURI {
+		t.Errorf("Expected default Mongo URI, obtained: %s", cfg.Server.MongoURI)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")})
+	if err == nil {
+		t.Error("Expected an error for a missing configuration file")
+	}
+}
+
+func TestLoadInvalidBatchSize(t *testing.T) {
+	t.Setenv(EnvConfigFile, "")
+
+	_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-batch-size", "0"})
+	if err == nil {
+		t.Error("Expected an error for a batch size of 0")
+	}
+
+	t.Setenv("FLACO_BATCH_SIZE", "many")
+	_, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err == nil {
+		t.Error("Expected an error for a non numeric batch size")
+	}
+}
+
+func TestLoadInvalidTransactions(t *testing.T) {
+	t.Setenv(EnvConfigFile, "")
+
+	_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-transactions", "sometimes"})
+	if err == nil {
+		t.Error("Expected an error for an unknown transactions mode")
+	}
+}
+
+func TestLoadAllowedTypes(t *testing.T) {
+	t.Setenv(EnvConfigFile, "")
+
+	// Items are trimmed and empty items dropped
+	t.Setenv("FLACO_ALLOWED_OPERATION_TYPES", " CREATE, READ ,,")
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Server.AllowedTypes, []string{"CREATE", "READ"}) {
+		t.Errorf("Expected allowed types [CREATE READ], obtained: %v", cfg.Server.AllowedTypes)
+	}
+
+	// An empty list accepts any type
+	cfg, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-allowed-types", ""})
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if len(cfg.Server.AllowedTypes) != 0 {
+		t.Errorf("Expected no allowed types, obtained: %v", cfg.Server.AllowedTypes)
+	}
+}
+
+func TestLoadInvalidMaxOperations(t *testing.T) {
+	t.Setenv(EnvConfigFile, "")
+
+	_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-max-operations", "-1"})
+	if err == nil {
+		t.Error("Expected an error for a negative maximum number of operations")
+	}
+}
+
+func TestLoadWatch(t *testing.T) {
+	t.Setenv(EnvConfigFile, "")
+	t.Setenv("FLACO_WATCH", "true")
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if !cfg.Client.Watch {
+		t.Error("Expected watch mode from environment")
+	}
+
+	// A boolean flag can be given without value, or with one to override the environment
+	cfg, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-watch=false"})
+	if err != nil || cfg.Client.Watch {
+		t.Errorf("Expected watch mode disabled by flag, obtained: %v (err: %v)", cfg, err)
+	}
+	t.Setenv("FLACO_WATCH", "")
+	if _, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), nil); err == nil {
+		t.Error("Expected an error for an invalid FLACO_WATCH")
+	}
+	os.Unsetenv("FLACO_WATCH")
+	cfg, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-watch"})
+	if err != nil || !cfg.Client.Watch {
+		t.Errorf("Expected watch mode enabled by flag, obtained: %v (err: %v)", cfg, err)
+	}
+}
+
+func TestLoadSections(t *testing.T) {
+	t.Setenv(EnvConfigFile, "")
+	t.Setenv("FLACO_DATABASE", "from_env")
+
+	// Only the flags of the requested sections are registered, the environment still applies to every section
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg, err := Load(fs, []string{"-data-dir", "/data/flag", "upload.json"}, SectionClient)
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if cfg.Client.DataDir != "/data/flag" || cfg.Server.Database != "from_env" || fs.Arg(0) != "upload.json" {
+		t.Errorf("Expected the client flag, the environment and the argument to be read, obtained: %+v %v", cfg, fs.Args())
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err = Load(fs, []string{"-database", "from_flag"}, SectionClient); err == nil {
+		t.Error("Expected an error for a flag of another section")
+	}
+}
+
+func TestLoadReadyTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flaco.yaml")
+	if err := os.WriteFile(path, []byte("client:\n  ready_timeout: 1m30s\n"), 0644); err != nil {
+		t.Fatalf("Unable to write configuration file: %v", err)
+	}
+	t.Setenv(EnvConfigFile, path)
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if cfg.Client.ReadyTimeout != 90*time.Second {
+		t.Errorf("Expected ready timeout from file: 1m30s, obtained: %v", cfg.Client.ReadyTimeout)
+	}
+
+	if _, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-ready-timeout", "10"}); err == nil {
+		t.Error("Expected an error for a duration without unit")
+	}
+}
+
+func TestLoadTLS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flaco.yaml")
+	content := "server:\n  tls_cert: server.pem\n  tls_key: server-key.pem\n  client_ca: ca.pem\n" +
+		"  client_identities:\n    site-reims: [reims-, marne-]\n" +
+		"  client_roles:\n    site-reims: [uploader]\n  method_roles:\n    ListDeviceStats: [reader, uploader]\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Unable to write configuration file: %v", err)
+	}
+	t.Setenv(EnvConfigFile, path)
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-client-cert", "reims.pem", "-client-key", "reims-key.pem"})
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Server.ClientIdentities["site-reims"], []string{"reims-", "marne-"}) {
+		t.Errorf("Expected the device prefixes of site-reims, obtained: %v", cfg.Server.ClientIdentities)
+	}
+	if !reflect.DeepEqual(cfg.Server.ClientRoles["site-reims"], []string{"uploader"}) || len(cfg.Server.MethodRoles["ListDeviceStats"]) != 2 {
+		t.Errorf("Expected the roles of site-reims and ListDeviceStats, obtained: %v and %v", cfg.Server.ClientRoles, cfg.Server.MethodRoles)
+	}
+	if !cfg.Client.UsesTLS() {
+		t.Error("Expected a client certificate to enable TLS")
+	}
+
+	// A certificate without its key is rejected
+	if _, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-tls-key", ""}); err == nil {
+		t.Error("Expected an error for a server certificate without key")
+	}
+	if _, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-client-cert", "reims.pem"}); err == nil {
+		t.Error("Expected an error for a client certificate without key")
+	}
+}
+
+func TestLoadTokens(t *testing.T) {
+	t.Setenv("FLACO_API_KEYS_FILE", "keys.yaml")
+	t.Setenv("FLACO_TOKEN", "env-token")
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-jwt-secret-file", "secret", "-token", "flag-token"})
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if cfg.Server.APIKeysFile != "keys.yaml" || cfg.Server.JWTSecretFile != "secret" {
+		t.Errorf("Expected keys.yaml and secret, obtained: %q and %q", cfg.Server.APIKeysFile, cfg.Server.JWTSecretFile)
+	}
+	if cfg.Client.Token != "flag-token" {
+		t.Errorf("Expected the flag to override the environment, obtained: %q", cfg.Client.Token)
+	}
+}
+
+func TestLoadLegacyAdminRoles(t *testing.T) {
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if cfg.Server.LegacyAdminRoles {
+		t.Error("Expected the clients without roles to be rejected by default")
+	}
+
+	t.Setenv("FLACO_LEGACY_ADMIN_ROLES", "true")
+	if cfg, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), nil); err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if !cfg.Server.LegacyAdminRoles {
+		t.Error("Expected the environment to enable the legacy admin roles")
+	}
+}
+
+func TestLoadMetricsAddr(t *testing.T) {
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if cfg.Server.MetricsAddr != "" {
+		t.Errorf("Expected the metrics endpoint to be disabled by default, obtained: %q", cfg.Server.MetricsAddr)
+	}
+
+	t.Setenv("FLACO_METRICS_ADDR", ":9464")
+	if cfg, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), nil); err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if cfg.Server.MetricsAddr != ":9464" {
+		t.Errorf("Expected :9464, obtained: %q", cfg.Server.MetricsAddr)
+	}
+}
+
+func TestLoadExampleFile(t *testing.T) {
+	t.Setenv(EnvConfigFile, filepath.Join("..", "flaco.example.yaml"))
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Error loading the example configuration: %v", err)
+	}
+	if cfg.Server.ListenAddr != Default().Server.ListenAddr || cfg.Client.UsesTLS() {
+		t.Errorf("Expected the example to hold the defaults, obtained: %+v", cfg)
+	}
+}
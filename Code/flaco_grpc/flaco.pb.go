@@ -9,6 +9,7 @@ package flaco_grpc
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -20,12 +21,15 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Request message for sending device information to the server
 type Request struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Device []*Device `protobuf:"bytes,1,rep,name=device,proto3" json:"device,omitempty"`
+	Device         []*Device `protobuf:"bytes,1,rep,name=device,proto3" json:"device,omitempty"`                                       // List of devices with their operations
+	IdempotencyKey string    `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Identifier of the batch (e.g. hash of the source file), a replayed batch is not stored again
+	Day            string    `protobuf:"bytes,3,opt,name=day,proto3" json:"day,omitempty"`                                             // Day the operations happened (e.g. "journee_1" or "2024-05-01"), used by the devices without their own day
 }
 
 func (x *Request) Reset() {
@@ -67,32 +71,593 @@ func (x *Request) GetDevice() []*Device {
 	return nil
 }
 
+func (x *Request) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *Request) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+// Device message representing a device and its operations
 type Device struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeviceName string       `protobuf:"bytes,1,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
-	Operation  []*Operation `protobuf:"bytes,2,rep,name=operation,proto3" json:"operation,omitempty"`
+	DeviceName string       `protobuf:"bytes,1,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"` // Name of the device
+	Operation  []*Operation `protobuf:"bytes,2,rep,name=operation,proto3" json:"operation,omitempty"`                     // List of operations performed on the device
+	Day        string       `protobuf:"bytes,3,opt,name=day,proto3" json:"day,omitempty"`                                 // Day the operations happened (empty to use the day of the request)
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Device) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *Device) GetOperation() []*Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+func (x *Device) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+// Operation message representing an operation performed on a device
+type Operation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type         string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                                      // Type of the operation
+	HasSucceeded bool                   `protobuf:"varint,2,opt,name=has_succeeded,json=hasSucceeded,proto3" json:"has_succeeded,omitempty"` // Success status of the operation
+	Timestamp    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                            // Time the operation happened (optional)
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Operation) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Operation) GetHasSucceeded() bool {
+	if x != nil {
+		return x.HasSucceeded
+	}
+	return false
+}
+
+func (x *Operation) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// Response message returned by the server
+type Response struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results  []*DeviceResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`    // Ingestion result of each device of the request, in request order
+	Replayed bool            `protobuf:"varint,2,opt,name=replayed,proto3" json:"replayed,omitempty"` // True when the batch had already been processed and the previous result is returned
+}
+
+func (x *Response) Reset() {
+	*x = Response{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Response) ProtoMessage() {}
+
+func (x *Response) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Response.ProtoReflect.Descriptor instead.
+func (*Response) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Response) GetResults() []*DeviceResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *Response) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+// Result message describing how the operations of a device were ingested
+type DeviceResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceName string      `protobuf:"bytes,1,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"` // Name of the device
+	Accepted   int64       `protobuf:"varint,2,opt,name=accepted,proto3" json:"accepted,omitempty"`                      // Number of operations stored
+	Rejected   int64       `protobuf:"varint,3,opt,name=rejected,proto3" json:"rejected,omitempty"`                      // Number of operations that could not be stored
+	Stat       *DeviceStat `protobuf:"bytes,4,opt,name=stat,proto3" json:"stat,omitempty"`                               // Aggregated statistics of the device once updated (unset when the update failed)
+	Error      string      `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`                             // Reason why the device could not be fully stored (empty on success)
+}
+
+func (x *DeviceResult) Reset() {
+	*x = DeviceResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeviceResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceResult) ProtoMessage() {}
+
+func (x *DeviceResult) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceResult.ProtoReflect.Descriptor instead.
+func (*DeviceResult) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeviceResult) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *DeviceResult) GetAccepted() int64 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *DeviceResult) GetRejected() int64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *DeviceResult) GetStat() *DeviceStat {
+	if x != nil {
+		return x.Stat
+	}
+	return nil
+}
+
+func (x *DeviceResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Summary message returned by the server once a stream of devices has been stored
+type IngestSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Devices    int64           `protobuf:"varint,1,opt,name=devices,proto3" json:"devices,omitempty"`       // Number of devices stored
+	Operations int64           `protobuf:"varint,2,opt,name=operations,proto3" json:"operations,omitempty"` // Number of operations stored
+	Results    []*DeviceResult `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`        // Ingestion result of each streamed device, in stream order
+	Replayed   bool            `protobuf:"varint,4,opt,name=replayed,proto3" json:"replayed,omitempty"`     // True when the batch had already been processed and the previous summary is returned
+}
+
+func (x *IngestSummary) Reset() {
+	*x = IngestSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IngestSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestSummary) ProtoMessage() {}
+
+func (x *IngestSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestSummary.ProtoReflect.Descriptor instead.
+func (*IngestSummary) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *IngestSummary) GetDevices() int64 {
+	if x != nil {
+		return x.Devices
+	}
+	return 0
+}
+
+func (x *IngestSummary) GetOperations() int64 {
+	if x != nil {
+		return x.Operations
+	}
+	return 0
+}
+
+func (x *IngestSummary) GetResults() []*DeviceResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *IngestSummary) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+// Request message for reading back the statistics of a single device
+type DeviceStatRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceName string `protobuf:"bytes,1,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"` // Name of the device to look up
+}
+
+func (x *DeviceStatRequest) Reset() {
+	*x = DeviceStatRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeviceStatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceStatRequest) ProtoMessage() {}
+
+func (x *DeviceStatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceStatRequest.ProtoReflect.Descriptor instead.
+func (*DeviceStatRequest) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeviceStatRequest) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+// DeviceStat message holding the aggregated operation counts of a device
+type DeviceStat struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceName string               `protobuf:"bytes,1,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"` // Name of the device
+	Total      int64                `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`                            // Total number of operations
+	Successful int64                `protobuf:"varint,3,opt,name=successful,proto3" json:"successful,omitempty"`                  // Number of successful operations
+	Failed     int64                `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`                          // Number of failed operations
+	Days       []*DayStat           `protobuf:"bytes,5,rep,name=days,proto3" json:"days,omitempty"`                               // Statistics of each day the device has operations for, sorted by day
+	Types      []*OperationTypeStat `protobuf:"bytes,6,rep,name=types,proto3" json:"types,omitempty"`                             // Statistics of each operation type of the device, sorted by type
+}
+
+func (x *DeviceStat) Reset() {
+	*x = DeviceStat{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeviceStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceStat) ProtoMessage() {}
+
+func (x *DeviceStat) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceStat.ProtoReflect.Descriptor instead.
+func (*DeviceStat) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeviceStat) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *DeviceStat) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *DeviceStat) GetSuccessful() int64 {
+	if x != nil {
+		return x.Successful
+	}
+	return 0
+}
+
+func (x *DeviceStat) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *DeviceStat) GetDays() []*DayStat {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *DeviceStat) GetTypes() []*OperationTypeStat {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+// DayStat message holding the operation counts of a device for a single day
+type DayStat struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Day        string `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`                // Day the operations happened
+	Total      int64  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`           // Total number of operations
+	Successful int64  `protobuf:"varint,3,opt,name=successful,proto3" json:"successful,omitempty"` // Number of successful operations
+	Failed     int64  `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`         // Number of failed operations
+}
+
+func (x *DayStat) Reset() {
+	*x = DayStat{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DayStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DayStat) ProtoMessage() {}
+
+func (x *DayStat) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DayStat.ProtoReflect.Descriptor instead.
+func (*DayStat) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DayStat) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+func (x *DayStat) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *DayStat) GetSuccessful() int64 {
+	if x != nil {
+		return x.Successful
+	}
+	return 0
+}
+
+func (x *DayStat) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+// OperationTypeStat message holding the counts of the operations of a single type
+type OperationTypeStat struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type       string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`              // Type of the operations ("UNSPECIFIED" for operations without type)
+	Total      int64  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`           // Total number of operations
+	Successful int64  `protobuf:"varint,3,opt,name=successful,proto3" json:"successful,omitempty"` // Number of successful operations
+	Failed     int64  `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`         // Number of failed operations
 }
 
-func (x *Device) Reset() {
-	*x = Device{}
+func (x *OperationTypeStat) Reset() {
+	*x = OperationTypeStat{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_flaco_grpc_flaco_proto_msgTypes[1]
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Device) String() string {
+func (x *OperationTypeStat) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Device) ProtoMessage() {}
+func (*OperationTypeStat) ProtoMessage() {}
 
-func (x *Device) ProtoReflect() protoreflect.Message {
-	mi := &file_flaco_grpc_flaco_proto_msgTypes[1]
+func (x *OperationTypeStat) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -103,51 +668,163 @@ func (x *Device) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Device.ProtoReflect.Descriptor instead.
-func (*Device) Descriptor() ([]byte, []int) {
-	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use OperationTypeStat.ProtoReflect.Descriptor instead.
+func (*OperationTypeStat) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *Device) GetDeviceName() string {
+func (x *OperationTypeStat) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *OperationTypeStat) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *OperationTypeStat) GetSuccessful() int64 {
+	if x != nil {
+		return x.Successful
+	}
+	return 0
+}
+
+func (x *OperationTypeStat) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+// Request message for reading the statistics by operation type
+type OperationTypeStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceName string `protobuf:"bytes,1,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"` // Device whose operations are broken down (empty for the operations of every device)
+}
+
+func (x *OperationTypeStatsRequest) Reset() {
+	*x = OperationTypeStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OperationTypeStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperationTypeStatsRequest) ProtoMessage() {}
+
+func (x *OperationTypeStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperationTypeStatsRequest.ProtoReflect.Descriptor instead.
+func (*OperationTypeStatsRequest) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *OperationTypeStatsRequest) GetDeviceName() string {
 	if x != nil {
 		return x.DeviceName
 	}
 	return ""
 }
 
-func (x *Device) GetOperation() []*Operation {
+// Response message holding the statistics by operation type
+type OperationTypeStatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stats []*OperationTypeStat `protobuf:"bytes,1,rep,name=stats,proto3" json:"stats,omitempty"` // Statistics of each operation type, the most failing type first
+}
+
+func (x *OperationTypeStatsResponse) Reset() {
+	*x = OperationTypeStatsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OperationTypeStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperationTypeStatsResponse) ProtoMessage() {}
+
+func (x *OperationTypeStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperationTypeStatsResponse.ProtoReflect.Descriptor instead.
+func (*OperationTypeStatsResponse) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *OperationTypeStatsResponse) GetStats() []*OperationTypeStat {
 	if x != nil {
-		return x.Operation
+		return x.Stats
 	}
 	return nil
 }
 
-type Operation struct {
+// Request message for listing device statistics page by page
+type ListDeviceStatsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Type         string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
-	HasSucceeded bool   `protobuf:"varint,2,opt,name=has_succeeded,json=hasSucceeded,proto3" json:"has_succeeded,omitempty"`
+	NameFilter string `protobuf:"bytes,1,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"` // Only keep devices whose name contains this value (empty keeps every device)
+	PageSize   int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`      // Maximum number of statistics returned (0 uses the server default)
+	PageToken  string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`    // Token returned by the previous page (empty for the first page)
 }
 
-func (x *Operation) Reset() {
-	*x = Operation{}
+func (x *ListDeviceStatsRequest) Reset() {
+	*x = ListDeviceStatsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_flaco_grpc_flaco_proto_msgTypes[2]
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Operation) String() string {
+func (x *ListDeviceStatsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Operation) ProtoMessage() {}
+func (*ListDeviceStatsRequest) ProtoMessage() {}
 
-func (x *Operation) ProtoReflect() protoreflect.Message {
-	mi := &file_flaco_grpc_flaco_proto_msgTypes[2]
+func (x *ListDeviceStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -158,48 +835,59 @@ func (x *Operation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
-func (*Operation) Descriptor() ([]byte, []int) {
-	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ListDeviceStatsRequest.ProtoReflect.Descriptor instead.
+func (*ListDeviceStatsRequest) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *Operation) GetType() string {
+func (x *ListDeviceStatsRequest) GetNameFilter() string {
 	if x != nil {
-		return x.Type
+		return x.NameFilter
 	}
 	return ""
 }
 
-func (x *Operation) GetHasSucceeded() bool {
+func (x *ListDeviceStatsRequest) GetPageSize() int32 {
 	if x != nil {
-		return x.HasSucceeded
+		return x.PageSize
 	}
-	return false
+	return 0
 }
 
-type Response struct {
+func (x *ListDeviceStatsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// Response message holding one page of device statistics
+type ListDeviceStatsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Stats         []*DeviceStat `protobuf:"bytes,1,rep,name=stats,proto3" json:"stats,omitempty"`                                        // Statistics of the devices in this page, sorted by device name
+	NextPageToken string        `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // Token to request the next page (empty when there are no more pages)
 }
 
-func (x *Response) Reset() {
-	*x = Response{}
+func (x *ListDeviceStatsResponse) Reset() {
+	*x = ListDeviceStatsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_flaco_grpc_flaco_proto_msgTypes[3]
+		mi := &file_flaco_grpc_flaco_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Response) String() string {
+func (x *ListDeviceStatsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Response) ProtoMessage() {}
+func (*ListDeviceStatsResponse) ProtoMessage() {}
 
-func (x *Response) ProtoReflect() protoreflect.Message {
-	mi := &file_flaco_grpc_flaco_proto_msgTypes[3]
+func (x *ListDeviceStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_flaco_grpc_flaco_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -210,35 +898,151 @@ func (x *Response) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Response.ProtoReflect.Descriptor instead.
-func (*Response) Descriptor() ([]byte, []int) {
-	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ListDeviceStatsResponse.ProtoReflect.Descriptor instead.
+func (*ListDeviceStatsResponse) Descriptor() ([]byte, []int) {
+	return file_flaco_grpc_flaco_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListDeviceStatsResponse) GetStats() []*DeviceStat {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *ListDeviceStatsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
 }
 
 var File_flaco_grpc_flaco_proto protoreflect.FileDescriptor
 
 var file_flaco_grpc_flaco_proto_rawDesc = []byte{
 	0x0a, 0x16, 0x66, 0x6c, 0x61, 0x63, 0x6f, 0x5f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x66, 0x6c, 0x61,
-	0x63, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2a, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x06, 0x64, 0x65,
-	0x76, 0x69, 0x63, 0x65, 0x22, 0x53, 0x0a, 0x06, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x1f,
-	0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12,
-	0x28, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09,
-	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x44, 0x0a, 0x09, 0x4f, 0x70, 0x65,
-	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x68, 0x61,
-	0x73, 0x5f, 0x73, 0x75, 0x63, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x0c, 0x68, 0x61, 0x73, 0x53, 0x75, 0x63, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x22,
-	0x0a, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x38, 0x0a, 0x0a, 0x44,
-	0x61, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2a, 0x0a, 0x13, 0x53, 0x65, 0x6e,
-	0x64, 0x44, 0x61, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x54, 0x6f, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
-	0x12, 0x08, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x09, 0x2e, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x1e, 0x5a, 0x1c, 0x66, 0x6c, 0x61, 0x63, 0x6f, 0x2f, 0x47,
-	0x52, 0x50, 0x43, 0x5f, 0x41, 0x4e, 0x44, 0x5f, 0x47, 0x4f, 0x2f, 0x66, 0x6c, 0x61, 0x63, 0x6f,
-	0x5f, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x63, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x65, 0x0a, 0x07, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x06, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74,
+	0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x64, 0x61, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x64, 0x61, 0x79,
+	0x22, 0x65, 0x0a, 0x06, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x28, 0x0a, 0x09, 0x6f,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x61, 0x79, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x64, 0x61, 0x79, 0x22, 0x7e, 0x0a, 0x09, 0x4f, 0x70, 0x65, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x68, 0x61, 0x73, 0x5f,
+	0x73, 0x75, 0x63, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0c, 0x68, 0x61, 0x73, 0x53, 0x75, 0x63, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x38, 0x0a,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x4f, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08,
+	0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08,
+	0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x22, 0x9e, 0x01, 0x0a, 0x0c, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63,
+	0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x61, 0x63,
+	0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74,
+	0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74,
+	0x65, 0x64, 0x12, 0x1f, 0x0a, 0x04, 0x73, 0x74, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0b, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x52, 0x04, 0x73,
+	0x74, 0x61, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x8e, 0x01, 0x0a, 0x0d, 0x49, 0x6e,
+	0x67, 0x65, 0x73, 0x74, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6f, 0x70, 0x65, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x27, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x22, 0x34, 0x0a, 0x11, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65,
+	0x22, 0xc3, 0x01, 0x0a, 0x0a, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x12,
+	0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x66, 0x75, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x75, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x66, 0x75, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x1c,
+	0x0a, 0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x44,
+	0x61, 0x79, 0x53, 0x74, 0x61, 0x74, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73, 0x12, 0x28, 0x0a, 0x05,
+	0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x53, 0x74, 0x61, 0x74, 0x52,
+	0x05, 0x74, 0x79, 0x70, 0x65, 0x73, 0x22, 0x69, 0x0a, 0x07, 0x44, 0x61, 0x79, 0x53, 0x74, 0x61,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x64, 0x61, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x75, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x66, 0x75, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x66, 0x75, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x61, 0x69,
+	0x6c, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65,
+	0x64, 0x22, 0x75, 0x0a, 0x11, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79,
+	0x70, 0x65, 0x53, 0x74, 0x61, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x66, 0x75, 0x6c, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x66, 0x75, 0x6c,
+	0x12, 0x16, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x22, 0x3c, 0x0a, 0x19, 0x4f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x46, 0x0a, 0x1a, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54,
+	0x79, 0x70, 0x65, 0x53, 0x74, 0x61, 0x74, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x22, 0x75,
+	0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x61, 0x6d, 0x65,
+	0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e,
+	0x61, 0x6d, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67,
+	0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61,
+	0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x64, 0x0a, 0x17, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x21, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0b, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x52, 0x05, 0x73, 0x74,
+	0x61, 0x74, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65,
+	0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65,
+	0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x32, 0xae, 0x02, 0x0a, 0x0a,
+	0x44, 0x61, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2a, 0x0a, 0x13, 0x53, 0x65,
+	0x6e, 0x64, 0x44, 0x61, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x54, 0x6f, 0x53, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x12, 0x08, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x09, 0x2e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x0d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x44, 0x61, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x07, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x1a, 0x0e, 0x2e, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79,
+	0x28, 0x01, 0x12, 0x30, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x12, 0x12, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0b, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x12, 0x44, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x17, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x18, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x50, 0x0a, 0x15, 0x47, 0x65,
+	0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x12, 0x1a, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54,
+	0x79, 0x70, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x1e, 0x5a, 0x1c,
+	0x66, 0x6c, 0x61, 0x63, 0x6f, 0x2f, 0x47, 0x52, 0x50, 0x43, 0x5f, 0x41, 0x4e, 0x44, 0x5f, 0x47,
+	0x4f, 0x2f, 0x66, 0x6c, 0x61, 0x63, 0x6f, 0x5f, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -253,23 +1057,50 @@ func file_flaco_grpc_flaco_proto_rawDescGZIP() []byte {
 	return file_flaco_grpc_flaco_proto_rawDescData
 }
 
-var file_flaco_grpc_flaco_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_flaco_grpc_flaco_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_flaco_grpc_flaco_proto_goTypes = []interface{}{
-	(*Request)(nil),   // 0: Request
-	(*Device)(nil),    // 1: Device
-	(*Operation)(nil), // 2: Operation
-	(*Response)(nil),  // 3: Response
+	(*Request)(nil),                    // 0: Request
+	(*Device)(nil),                     // 1: Device
+	(*Operation)(nil),                  // 2: Operation
+	(*Response)(nil),                   // 3: Response
+	(*DeviceResult)(nil),               // 4: DeviceResult
+	(*IngestSummary)(nil),              // 5: IngestSummary
+	(*DeviceStatRequest)(nil),          // 6: DeviceStatRequest
+	(*DeviceStat)(nil),                 // 7: DeviceStat
+	(*DayStat)(nil),                    // 8: DayStat
+	(*OperationTypeStat)(nil),          // 9: OperationTypeStat
+	(*OperationTypeStatsRequest)(nil),  // 10: OperationTypeStatsRequest
+	(*OperationTypeStatsResponse)(nil), // 11: OperationTypeStatsResponse
+	(*ListDeviceStatsRequest)(nil),     // 12: ListDeviceStatsRequest
+	(*ListDeviceStatsResponse)(nil),    // 13: ListDeviceStatsResponse
+	(*timestamppb.Timestamp)(nil),      // 14: google.protobuf.Timestamp
 }
 var file_flaco_grpc_flaco_proto_depIdxs = []int32{
-	1, // 0: Request.device:type_name -> Device
-	2, // 1: Device.operation:type_name -> Operation
-	0, // 2: DayService.SendDayInfoToServer:input_type -> Request
-	3, // 3: DayService.SendDayInfoToServer:output_type -> Response
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	1,  // 0: Request.device:type_name -> Device
+	2,  // 1: Device.operation:type_name -> Operation
+	14, // 2: Operation.timestamp:type_name -> google.protobuf.Timestamp
+	4,  // 3: Response.results:type_name -> DeviceResult
+	7,  // 4: DeviceResult.stat:type_name -> DeviceStat
+	4,  // 5: IngestSummary.results:type_name -> DeviceResult
+	8,  // 6: DeviceStat.days:type_name -> DayStat
+	9,  // 7: DeviceStat.types:type_name -> OperationTypeStat
+	9,  // 8: OperationTypeStatsResponse.stats:type_name -> OperationTypeStat
+	7,  // 9: ListDeviceStatsResponse.stats:type_name -> DeviceStat
+	0,  // 10: DayService.SendDayInfoToServer:input_type -> Request
+	1,  // 11: DayService.StreamDayInfo:input_type -> Device
+	6,  // 12: DayService.GetDeviceStat:input_type -> DeviceStatRequest
+	12, // 13: DayService.ListDeviceStats:input_type -> ListDeviceStatsRequest
+	10, // 14: DayService.GetOperationTypeStats:input_type -> OperationTypeStatsRequest
+	3,  // 15: DayService.SendDayInfoToServer:output_type -> Response
+	5,  // 16: DayService.StreamDayInfo:output_type -> IngestSummary
+	7,  // 17: DayService.GetDeviceStat:output_type -> DeviceStat
+	13, // 18: DayService.ListDeviceStats:output_type -> ListDeviceStatsResponse
+	11, // 19: DayService.GetOperationTypeStats:output_type -> OperationTypeStatsResponse
+	15, // [15:20] is the sub-list for method output_type
+	10, // [10:15] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_flaco_grpc_flaco_proto_init() }
@@ -326,6 +1157,126 @@ func file_flaco_grpc_flaco_proto_init() {
 				return nil
 			}
 		}
+		file_flaco_grpc_flaco_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeviceResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flaco_grpc_flaco_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IngestSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flaco_grpc_flaco_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeviceStatRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flaco_grpc_flaco_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeviceStat); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flaco_grpc_flaco_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DayStat); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flaco_grpc_flaco_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OperationTypeStat); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flaco_grpc_flaco_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OperationTypeStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flaco_grpc_flaco_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OperationTypeStatsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flaco_grpc_flaco_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDeviceStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flaco_grpc_flaco_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDeviceStatsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -333,7 +1284,7 @@ func file_flaco_grpc_flaco_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_flaco_grpc_flaco_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
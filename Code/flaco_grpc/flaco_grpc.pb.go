@@ -22,7 +22,17 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type DayServiceClient interface {
+	// RPC method for sending device information to the server
 	SendDayInfoToServer(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	// RPC method for streaming device information to the server one device at a time.
+	// The idempotency key of the batch can be sent in the "x-idempotency-key" metadata.
+	StreamDayInfo(ctx context.Context, opts ...grpc.CallOption) (DayService_StreamDayInfoClient, error)
+	// RPC method for reading back the statistics of a single device
+	GetDeviceStat(ctx context.Context, in *DeviceStatRequest, opts ...grpc.CallOption) (*DeviceStat, error)
+	// RPC method for listing the statistics of every device, with pagination and name filtering
+	ListDeviceStats(ctx context.Context, in *ListDeviceStatsRequest, opts ...grpc.CallOption) (*ListDeviceStatsResponse, error)
+	// RPC method for reading the statistics by operation type, of every device or of a single one
+	GetOperationTypeStats(ctx context.Context, in *OperationTypeStatsRequest, opts ...grpc.CallOption) (*OperationTypeStatsResponse, error)
 }
 
 type dayServiceClient struct {
@@ -42,11 +52,82 @@ func (c *dayServiceClient) SendDayInfoToServer(ctx context.Context, in *Request,
 	return out, nil
 }
 
+func (c *dayServiceClient) StreamDayInfo(ctx context.Context, opts ...grpc.CallOption) (DayService_StreamDayInfoClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DayService_ServiceDesc.Streams[0], "/DayService/StreamDayInfo", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dayServiceStreamDayInfoClient{stream}
+	return x, nil
+}
+
+type DayService_StreamDayInfoClient interface {
+	Send(*Device) error
+	CloseAndRecv() (*IngestSummary, error)
+	grpc.ClientStream
+}
+
+type dayServiceStreamDayInfoClient struct {
+	grpc.ClientStream
+}
+
+func (x *dayServiceStreamDayInfoClient) Send(m *Device) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dayServiceStreamDayInfoClient) CloseAndRecv() (*IngestSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(IngestSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dayServiceClient) GetDeviceStat(ctx context.Context, in *DeviceStatRequest, opts ...grpc.CallOption) (*DeviceStat, error) {
+	out := new(DeviceStat)
+	err := c.cc.Invoke(ctx, "/DayService/GetDeviceStat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dayServiceClient) ListDeviceStats(ctx context.Context, in *ListDeviceStatsRequest, opts ...grpc.CallOption) (*ListDeviceStatsResponse, error) {
+	out := new(ListDeviceStatsResponse)
+	err := c.cc.Invoke(ctx, "/DayService/ListDeviceStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dayServiceClient) GetOperationTypeStats(ctx context.Context, in *OperationTypeStatsRequest, opts ...grpc.CallOption) (*OperationTypeStatsResponse, error) {
+	out := new(OperationTypeStatsResponse)
+	err := c.cc.Invoke(ctx, "/DayService/GetOperationTypeStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DayServiceServer is the server API for DayService service.
 // All implementations must embed UnimplementedDayServiceServer
 // for forward compatibility
 type DayServiceServer interface {
+	// RPC method for sending device information to the server
 	SendDayInfoToServer(context.Context, *Request) (*Response, error)
+	// RPC method for streaming device information to the server one device at a time.
+	// The idempotency key of the batch can be sent in the "x-idempotency-key" metadata.
+	StreamDayInfo(DayService_StreamDayInfoServer) error
+	// RPC method for reading back the statistics of a single device
+	GetDeviceStat(context.Context, *DeviceStatRequest) (*DeviceStat, error)
+	// RPC method for listing the statistics of every device, with pagination and name filtering
+	ListDeviceStats(context.Context, *ListDeviceStatsRequest) (*ListDeviceStatsResponse, error)
+	// RPC method for reading the statistics by operation type, of every device or of a single one
+	GetOperationTypeStats(context.Context, *OperationTypeStatsRequest) (*OperationTypeStatsResponse, error)
 	mustEmbedUnimplementedDayServiceServer()
 }
 
@@ -57,6 +138,18 @@ type UnimplementedDayServiceServer struct {
 func (UnimplementedDayServiceServer) SendDayInfoToServer(context.Context, *Request) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendDayInfoToServer not implemented")
 }
+func (UnimplementedDayServiceServer) StreamDayInfo(DayService_StreamDayInfoServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDayInfo not implemented")
+}
+func (UnimplementedDayServiceServer) GetDeviceStat(context.Context, *DeviceStatRequest) (*DeviceStat, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeviceStat not implemented")
+}
+func (UnimplementedDayServiceServer) ListDeviceStats(context.Context, *ListDeviceStatsRequest) (*ListDeviceStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeviceStats not implemented")
+}
+func (UnimplementedDayServiceServer) GetOperationTypeStats(context.Context, *OperationTypeStatsRequest) (*OperationTypeStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperationTypeStats not implemented")
+}
 func (UnimplementedDayServiceServer) mustEmbedUnimplementedDayServiceServer() {}
 
 // UnsafeDayServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +181,86 @@ func _DayService_SendDayInfoToServer_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DayService_StreamDayInfo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DayServiceServer).StreamDayInfo(&dayServiceStreamDayInfoServer{stream})
+}
+
+type DayService_StreamDayInfoServer interface {
+	SendAndClose(*IngestSummary) error
+	Recv() (*Device, error)
+	grpc.ServerStream
+}
+
+type dayServiceStreamDayInfoServer struct {
+	grpc.ServerStream
+}
+
+func (x *dayServiceStreamDayInfoServer) SendAndClose(m *IngestSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dayServiceStreamDayInfoServer) Recv() (*Device, error) {
+	m := new(Device)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DayService_GetDeviceStat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceStatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DayServiceServer).GetDeviceStat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DayService/GetDeviceStat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DayServiceServer).GetDeviceStat(ctx, req.(*DeviceStatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DayService_ListDeviceStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeviceStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DayServiceServer).ListDeviceStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DayService/ListDeviceStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DayServiceServer).ListDeviceStats(ctx, req.(*ListDeviceStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DayService_GetOperationTypeStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OperationTypeStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DayServiceServer).GetOperationTypeStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DayService/GetOperationTypeStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DayServiceServer).GetOperationTypeStats(ctx, req.(*OperationTypeStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DayService_ServiceDesc is the grpc.ServiceDesc for DayService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,7 +272,25 @@ var DayService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendDayInfoToServer",
 			Handler:    _DayService_SendDayInfoToServer_Handler,
 		},
+		{
+			MethodName: "GetDeviceStat",
+			Handler:    _DayService_GetDeviceStat_Handler,
+		},
+		{
+			MethodName: "ListDeviceStats",
+			Handler:    _DayService_ListDeviceStats_Handler,
+		},
+		{
+			MethodName: "GetOperationTypeStats",
+			Handler:    _DayService_GetOperationTypeStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDayInfo",
+			Handler:       _DayService_StreamDayInfo_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "flaco_grpc/flaco.proto",
 }
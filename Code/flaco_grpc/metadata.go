@@ -0,0 +1,5 @@
+package flaco_grpc
+
+// IdempotencyKeyMetadata is the gRPC metadata key carrying the idempotency key of a StreamDayInfo batch,
+// the streaming counterpart of Request.IdempotencyKey
+const IdempotencyKeyMetadata = "x-idempotency-key"
@@ -0,0 +1,163 @@
+package serveur
+
+import (
+	"context"
+	"flaco/grpc_and_go/flaco_grpc"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Role grants a client the calls of a group of methods
+type Role string
+
+const (
+	RoleUploader Role = "uploader" // Uploads the operations of its devices, e.g. the client of a site
+	RoleReader   Role = "reader"   // Reads the statistics, e.g. a dashboard
+	RoleAdmin    Role = "admin"    // Calls every method
+)
+
+// DefaultMethodRoles is the roles allowed to call each method of the DayService, besides admins which may call any
+// method. The methods it does not list are reserved to admins.
+var DefaultMethodRoles = map[string][]Role{
+	"SendDayInfoToServer":   {RoleUploader},
+	"StreamDayInfo":         {RoleUploader},
+	"GetDeviceStat":         {RoleReader},
+	"ListDeviceStats":       {RoleReader},
+	"GetOperationTypeStats": {RoleReader},
+}
+
+// auditLog records the calls denied to the clients
+var auditLog = log.New(os.Stderr, "[AUDIT] => ", log.LstdFlags)
+
+// parseRoles checks the names of the roles of a client, which must have some. With legacyAdmin, a client without roles
+// is an admin instead, so that the clients of the servers configured before roles existed keep calling every method.
+func parseRoles(names []string, legacyAdmin bool) ([]Role, error) {
+	if len(names) == 0 {
+		if legacyAdmin {
+			return []Role{RoleAdmin}, nil
+		}
+		return nil, fmt.Errorf("no roles, expected some of %s, %s and %s", RoleUploader, RoleReader, RoleAdmin)
+	}
+	roles := make([]Role, len(names))
+	for i, name := range names {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, err
+		}
+		roles[i] = role
+	}
+	return roles, nil
+}
+
+// ParseRole checks the name of a role
+func ParseRole(name string) (Role, error) {
+	switch role := Role(name); role {
+	case RoleUploader, RoleReader, RoleAdmin:
+		return role, nil
+	default:
+		return "", fmt.Errorf("unknown role %q, expected %s, %s or %s", name, RoleUploader, RoleReader, RoleAdmin)
+	}
+}
+
+// HasRole tells whether the identity, and every identity the client was given before it, is an admin or has one of
+// roles. A nil identity, the one of the requests when authentication is disabled, has every role.
+func (i *Identity) HasRole(roles ...Role) bool {
+	if i == nil {
+		return true
+	}
+	for _, role := range i.Roles {
+		if role == RoleAdmin || slices.Contains(roles, role) {
+			return i.outer.HasRole(roles...)
+		}
+	}
+	return false
+}
+
+// authorizer allows the calls of the identified clients according to their roles
+type authorizer map[string][]Role // Roles allowed to call each full method name, besides admins
+
+// newAuthorizer returns the authorizer of the default policy, overridden by the roles configured for some methods
+func newAuthorizer(methodRoles map[string][]string) (authorizer, error) {
+	service := flaco_grpc.DayService_ServiceDesc
+	a := authorizer{}
+	for name, roles := range DefaultMethodRoles {
+		a["/"+service.ServiceName+"/"+name] = roles
+	}
+	for name, names := range methodRoles {
+		if !hasMethod(service, name) {
+			return nil, fmt.Errorf("method roles: unknown method %q of %s", name, service.ServiceName)
+		}
+		roles := make([]Role, len(names)) // An empty list reserves the method to admins
+		for i, roleName := range names {
+			role, err := ParseRole(roleName)
+			if err != nil {
+				return nil, fmt.Errorf("method roles: %s: %w", name, err)
+			}
+			roles[i] = role
+		}
+		a["/"+service.ServiceName+"/"+name] = roles
+	}
+	return a, nil
+}
+
+// hasMethod tells whether service has a unary or streaming method named name
+func hasMethod(service grpc.ServiceDesc, name string) bool {
+	for _, method := range service.Methods {
+		if method.MethodName == name {
+			return true
+		}
+	}
+	for _, stream := range service.Streams {
+		if stream.StreamName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize rejects the calls the roles of the client do not allow, once the client is identified
+func (a authorizer) authorize(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, healthServicePrefix) {
+		return ctx, nil
+	}
+	identity := IdentityFromContext(ctx)
+	if identity.HasRole(a[method]...) {
+		return ctx, nil
+	}
+	return nil, status.Errorf(codes.PermissionDenied, "%s may not call %s", identity.Name, method)
+}
+
+// audit logs the call of method when it was denied, whether its client could not be authenticated or was not allowed
+func audit(ctx context.Context, method string, err error) {
+	code := status.Code(err)
+	if code != codes.Unauthenticated && code != codes.PermissionDenied {
+		return
+	}
+	addr := "unknown address"
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	auditLog.Printf("%s denied %s to %s: %s", code, method, addr, status.Convert(err).Message())
+}
+
+// auditUnary audits the unary calls, it runs before the identification so that the failed ones are audited too
+func auditUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	audit(ctx, info.FullMethod, err)
+	return resp, err
+}
+
+// auditStream audits the streaming calls
+func auditStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+	audit(ss.Context(), info.FullMethod, err)
+	return err
+}
@@ -0,0 +1,162 @@
+package serveur
+
+import (
+	"context"
+	"errors"
+	"flaco/grpc_and_go/flaco_grpc"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Handle is a gRPC server started in the background, see Connect and Serve
+type Handle struct {
+	ready chan struct{} // Closed once the server accepts requests
+	done  chan struct{} // Closed once the server has stopped
+
+	once sync.Once
+	err  error    // Reason the server stopped, set before done is closed
+	addr net.Addr // Address the server listens on, set before ready is closed
+
+	grpcServer *grpc.Server // Set before ready is closed
+	server     *Server      // Set before ready is closed
+
+	metrics     *http.Server // HTTP server of the metrics endpoint, nil when disabled, set before ready is closed
+	metricsAddr net.Addr     // Address the metrics are served on, set before ready is closed
+}
+
+// newHandle creates the handle of a server that is not ready yet
+func newHandle() *Handle {
+	return &Handle{ready: make(chan struct{}), done: make(chan struct{})}
+}
+
+// Serve serves server on listener in the background and returns its handle, which is ready as soon as the server
+// accepts requests. The standard gRPC health service is registered along with the DayService, so that clients can wait
+// for the server to be ready. The store of server is closed on Shutdown.
+func Serve(listener net.Listener, server *Server, options ...grpc.ServerOption) *Handle {
+	handle := newHandle()
+	go handle.serve(listener, server, options...)
+	return handle
+}
+
+// serve registers the services on a new gRPC server, marks the handle ready and serves until the server stops
+func (h *Handle) serve(listener net.Listener, server *Server, options ...grpc.ServerOption) {
+	// Every request is measured, the ones rejected by the other interceptors included
+	options = append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(metricsUnary), grpc.ChainStreamInterceptor(metricsStream)}, options...)
+	s := grpc.NewServer(options...)                // Create a new gRPC server
+	flaco_grpc.RegisterDayServiceServer(s, server) // Register the DayService server
+
+	healthServer := health.NewServer() // Reports every service as serving
+	healthgrpc.RegisterHealthServer(s, healthServer)
+
+	h.addr, h.grpcServer, h.server = listener.Addr(), s, server
+	close(h.ready)
+	if err := s.Serve(listener); err != nil {
+		h.stop(fmt.Errorf("failed to serve: %w", err))
+		return
+	}
+	h.stop(nil)
+}
+
+// serveMetrics serves the metrics endpoint on listener in the background, until Shutdown
+func (h *Handle) serveMetrics(listener net.Listener) {
+	h.metrics, h.metricsAddr = newMetricsServer(), listener.Addr()
+	go func() {
+		if err := h.metrics.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("[LOGS] => Metrics endpoint stopped: %v\n", err)
+		}
+	}()
+}
+
+// stop records why the server stopped and signals it
+func (h *Handle) stop(err error) {
+	h.once.Do(func() {
+		h.err = err
+		close(h.done)
+	})
+}
+
+// Shutdown stops the server gracefully: it stops accepting connections and requests, waits for the requests in
+// progress to complete, then closes the store. When ctx expires first, the requests still in progress are cancelled
+// and the store is closed once they have returned. A server still starting is waited for first.
+func (h *Handle) Shutdown(ctx context.Context) error {
+	select {
+	case <-h.ready:
+	case <-h.done:
+		return nil // The server failed to start, there is nothing to stop
+	case <-ctx.Done():
+		return fmt.Errorf("server not started: %w", ctx.Err())
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		h.grpcServer.GracefulStop() // Returns once every request has completed
+		close(stopped)
+	}()
+	var err error
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		h.grpcServer.Stop() // Closes the connections and cancels the requests in progress
+		<-stopped
+		err = fmt.Errorf("forced shutdown: %w", ctx.Err())
+	}
+
+	if h.metrics != nil {
+		h.metrics.Close() // Scrapes are short, the metrics of the last requests are lost anyway
+	}
+
+	// Stop does not wait for the cancelled requests to return, the store must not be closed under their feet
+	h.server.inFlight.Wait()
+	if closeErr := h.server.Store.Close(context.WithoutCancel(ctx)); err == nil && closeErr != nil {
+		err = fmt.Errorf("closing store: %w", closeErr)
+	}
+	<-h.done
+	return err
+}
+
+// Ready is closed once the listener is bound and the store reachable, so that the server accepts requests.
+// It is never closed when the server fails to start.
+func (h *Handle) Ready() <-chan struct{} {
+	return h.ready
+}
+
+// Done is closed once the server has stopped, or has failed to start
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Err returns the reason the server stopped, once Done is closed
+func (h *Handle) Err() error {
+	<-h.done
+	return h.err
+}
+
+// WaitReady waits until the server accepts requests, and fails if it could not start or ctx expired first
+func (h *Handle) WaitReady(ctx context.Context) error {
+	select {
+	case <-h.ready:
+		return nil
+	case <-h.done:
+		return h.err
+	case <-ctx.Done():
+		return fmt.Errorf("server not ready: %w", ctx.Err())
+	}
+}
+
+// MetricsAddr returns the address the metrics endpoint listens on once the server is ready, nil when it is disabled
+func (h *Handle) MetricsAddr() net.Addr {
+	<-h.ready
+	return h.metricsAddr
+}
+
+// Addr returns the address the server listens on, once it is ready
+func (h *Handle) Addr() net.Addr {
+	<-h.ready
+	return h.addr
+}
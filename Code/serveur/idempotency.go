@@ -0,0 +1,96 @@
+package serveur
+
+import (
+	"context"
+	"errors"
+	"flaco/grpc_and_go/flaco_grpc"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// Prefixes of the recorded batch keys, so that a same key used by both RPCs does not mix their result types
+const (
+	requestBatchPrefix = "request/"
+	streamBatchPrefix  = "stream/"
+)
+
+// keyedMutex serializes the holders of a same key, so that concurrent replays of a batch are only processed once
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is the lock of a single key, removed once nobody holds or waits for it
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// Lock waits until no one else holds key and returns the function releasing it
+func (k *keyedMutex) Lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyLock)
+	}
+	lock, ok := k.locks[key]
+	if !ok {
+		lock = &keyLock{}
+		k.locks[key] = lock
+	}
+	lock.refs++
+	k.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		k.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// recordedKey returns the key a batch sent with key is recorded under. Once clients are authenticated, the batches are
+// recorded per client, so that a client can neither have the batches of another client skipped by sending their key
+// first, nor read their results by replaying them.
+func recordedKey(ctx context.Context, prefix string, key string) string {
+	for identity := IdentityFromContext(ctx); identity != nil; identity = identity.outer {
+		prefix += identity.Name + "\x00"
+	}
+	return prefix + key
+}
+
+// findBatch loads the result recorded for the batch into result and reports whether the batch was already processed
+func findBatch(ctx context.Context, store Store, key string, result proto.Message) (bool, error) {
+	data, err := store.FindBatchResult(ctx, key)
+	if errors.Is(err, ErrBatchNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, proto.Unmarshal(data, result)
+}
+
+// saveBatch records the result of a processed batch
+func saveBatch(ctx context.Context, store Store, key string, result proto.Message) error {
+	data, err := proto.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return store.SaveBatchResult(ctx, key, data)
+}
+
+// streamIdempotencyKey returns the idempotency key sent in the metadata of a stream (empty when there is none)
+func streamIdempotencyKey(ctx context.Context) string {
+	values := metadata.ValueFromIncomingContext(ctx, flaco_grpc.IdempotencyKeyMetadata)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
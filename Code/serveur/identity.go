@@ -0,0 +1,229 @@
+package serveur
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flaco/grpc_and_go/config"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// healthServicePrefix prefixes the methods of the gRPC health service, which any client may call to wait for the server
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// Identity is the authenticated client of a request, along with the devices it may upload and the methods it may call
+type Identity struct {
+	Name           string   // Name of the client, e.g. the common name of its certificate or the subject of its token
+	DevicePrefixes []string // Prefixes of the names of the devices the client may upload, the empty prefix allows any device
+	Roles          []Role   // Roles of the client, see authorizer
+
+	outer *Identity // Identity the client was given by a previous authentication of the request, if any
+}
+
+// MayUpload tells whether the identity, and every identity the client was given before it, may upload the
+// operations of the named device. A nil identity, the one of the requests when authentication is disabled, may
+// upload any device.
+func (i *Identity) MayUpload(deviceName string) bool {
+	if i == nil {
+		return true
+	}
+	for _, prefix := range i.DevicePrefixes {
+		if strings.HasPrefix(deviceName, prefix) {
+			return i.outer.MayUpload(deviceName)
+		}
+	}
+	return false
+}
+
+// identityKey is the context key of the Identity of a request
+type identityKey struct{}
+
+// withIdentity returns a copy of ctx holding identity. When the client was already identified, e.g. by its certificate
+// then by its token, both identities must allow a device for the client to upload it.
+func withIdentity(ctx context.Context, identity *Identity) context.Context {
+	identity.outer = IdentityFromContext(ctx)
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity of the client of a request, nil when the request is not authenticated
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// checkUpload rejects the devices the client of the request may not upload, as a whole, field being the position of
+// the device in the request
+func checkUpload(ctx context.Context, field string, deviceName string) error {
+	identity := IdentityFromContext(ctx)
+	if identity.MayUpload(deviceName) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "%s.device_name: %s may not upload device %q", field, identity.Name, deviceName)
+}
+
+// serverOptions returns the options of the gRPC server for the configured security: its TLS certificate and the
+// identification of the clients, by their certificate with mutual TLS, then by their bearer token when tokens are
+// configured
+func serverOptions(cfg config.ServerConfig) ([]grpc.ServerOption, error) {
+	var options []grpc.ServerOption
+	var identifiers []identifier
+	if cfg.TLSCert != "" {
+		creds, err := tlsCredentials(cfg)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(creds))
+		if cfg.ClientCA != "" {
+			certificates, err := newCertificateIdentities(cfg)
+			if err != nil {
+				return nil, err
+			}
+			identifiers = append(identifiers, certificates.identify)
+		}
+	}
+	if cfg.APIKeysFile != "" || cfg.JWTSecretFile != "" {
+		tokens, err := loadTokenAuthenticator(cfg)
+		if err != nil {
+			return nil, err
+		}
+		identifiers = append(identifiers, tokens.identify)
+	}
+
+	if len(identifiers) == 0 {
+		return options, nil // Every client may call every method
+	}
+
+	// The roles of the client are checked once it is identified by every means, the calls denied along the way audited
+	authorize, err := newAuthorizer(cfg.MethodRoles)
+	if err != nil {
+		return nil, err
+	}
+	identifiers = append(identifiers, authorize.authorize)
+	options = append(options, grpc.ChainUnaryInterceptor(auditUnary), grpc.ChainStreamInterceptor(auditStream))
+
+	// The first identifier runs first, so that the token of a client is only checked once its certificate is
+	for _, identify := range identifiers {
+		options = append(options, grpc.ChainUnaryInterceptor(identify.unary), grpc.ChainStreamInterceptor(identify.stream))
+	}
+	return options, nil
+}
+
+// tlsCredentials returns the TLS credentials of the server, verifying the client certificates with mutual TLS
+func tlsCredentials(cfg config.ServerConfig) (credentials.TransportCredentials, error) {
+	certificate, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+	if cfg.ClientCA != "" {
+		pool, err := loadCertPool(cfg.ClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("loading client CA: %w", err)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// loadCertPool reads the PEM certificates of the file at path
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no PEM certificate found in " + path)
+	}
+	return pool, nil
+}
+
+// certificateIdentities identifies the clients by the common name of their certificate
+type certificateIdentities struct {
+	prefixes map[string][]string // Prefixes of the devices each name may upload, any name may upload any device when empty
+	roles    map[string][]Role   // Roles of each name, a name without roles may only call the health service
+
+	legacyAdmin bool // Every name is an admin when roles is empty
+}
+
+// newCertificateIdentities checks the device prefixes and the roles of the client certificates of the configuration
+func newCertificateIdentities(cfg config.ServerConfig) (*certificateIdentities, error) {
+	c := &certificateIdentities{prefixes: cfg.ClientIdentities, roles: map[string][]Role{}, legacyAdmin: cfg.LegacyAdminRoles}
+	for name, names := range cfg.ClientRoles {
+		roles, err := parseRoles(names, cfg.LegacyAdminRoles)
+		if err != nil {
+			return nil, fmt.Errorf("client roles: %s: %w", name, err)
+		}
+		c.roles[name] = roles
+	}
+	return c, nil
+}
+
+// identify adds the identity of the client certificate of the request to ctx
+func (c *certificateIdentities) identify(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, healthServicePrefix) {
+		return ctx, nil
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "client certificate required")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "client certificate required")
+	}
+
+	name := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	identity := &Identity{Name: name, DevicePrefixes: []string{""}, Roles: c.roles[name]}
+	if len(c.prefixes) > 0 {
+		if identity.DevicePrefixes, ok = c.prefixes[name]; !ok {
+			return nil, status.Errorf(codes.PermissionDenied, "client certificate %q is not allowed", name)
+		}
+	}
+	if len(c.roles) == 0 && c.legacyAdmin {
+		identity.Roles = []Role{RoleAdmin}
+	}
+	return withIdentity(ctx, identity), nil
+}
+
+// identifier adds the identity of the client of a request to ctx, or rejects the request
+type identifier func(ctx context.Context, method string) (context.Context, error)
+
+// unary identifies the client of a unary request
+func (identify identifier) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := identify(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// stream identifies the client of a streaming request
+func (identify identifier) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := identify(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// contextStream is a server stream whose context is replaced, to pass values to the stream handlers
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the replaced context
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
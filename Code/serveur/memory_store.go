@@ -0,0 +1,248 @@
+package serveur
+
+import (
+	"context"
+	"flaco/grpc_and_go/flaco_grpc"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// MemoryStore is a Store keeping operations and statistics in memory, used to run the server without MongoDB.
+// Its transactions are serialized, and writes made outside of a transaction must not run concurrently with one.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	txMu       sync.Mutex                               // Serializes the transactions
+	operations map[string][]*flaco_grpc.Operation       // Raw operations by device name
+	stats      map[string]*flaco_grpc.DeviceStat        // Aggregated statistics by device name
+	types      map[string]*flaco_grpc.OperationTypeStat // Aggregated statistics of every device by operation type
+	batches    map[string][]byte                        // Results of the processed batches by idempotency key
+}
+
+// NewMemoryStore creates an empty in-memory Store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		operations: make(map[string][]*flaco_grpc.Operation),
+		stats:      make(map[string]*flaco_grpc.DeviceStat),
+		types:      make(map[string]*flaco_grpc.OperationTypeStat),
+		batches:    make(map[string][]byte),
+	}
+}
+
+// InsertOperations appends copies of the operations to the ones of the device
+func (m *MemoryStore) InsertOperations(ctx context.Context, device *flaco_grpc.Device) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, operation := range device.Operation {
+		m.operations[device.DeviceName] = append(m.operations[device.DeviceName], proto.Clone(operation).(*flaco_grpc.Operation))
+	}
+	return int64(len(device.Operation)), nil
+}
+
+// UpsertDeviceStat adds the counts of stat to the statistics of the device
+func (m *MemoryStore) UpsertDeviceStat(ctx context.Context, stat *DeviceStat) (*flaco_grpc.DeviceStat, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.stats[stat.DeviceName]
+	if !ok {
+		current = &flaco_grpc.DeviceStat{DeviceName: stat.DeviceName}
+		m.stats[stat.DeviceName] = current
+	}
+	current.Total += stat.NbTotalOp
+	current.Successful += stat.NbOpSuccess
+	current.Failed += stat.NbOpFailed
+
+	if stat.Day != "" {
+		// Keep the days sorted, as the MongoDB store returns them
+		i := sort.Search(len(current.Days), func(i int) bool { return current.Days[i].Day >= stat.Day })
+		if i == len(current.Days) || current.Days[i].Day != stat.Day {
+			current.Days = append(current.Days, nil)
+			copy(current.Days[i+1:], current.Days[i:])
+			current.Days[i] = &flaco_grpc.DayStat{Day: stat.Day}
+		}
+		current.Days[i].Total += stat.NbTotalOp
+		current.Days[i].Successful += stat.NbOpSuccess
+		current.Days[i].Failed += stat.NbOpFailed
+	}
+
+	for operationType, typeStat := range stat.ByType {
+		// The types are kept sorted as well
+		i := sort.Search(len(current.Types), func(i int) bool { return current.Types[i].Type >= operationType })
+		if i == len(current.Types) || current.Types[i].Type != operationType {
+			current.Types = append(current.Types, nil)
+			copy(current.Types[i+1:], current.Types[i:])
+			current.Types[i] = &flaco_grpc.OperationTypeStat{Type: operationType}
+		}
+		current.Types[i].Total += typeStat.NbTotalOp
+		current.Types[i].Successful += typeStat.NbOpSuccess
+		current.Types[i].Failed += typeStat.NbOpFailed
+	}
+	return proto.Clone(current).(*flaco_grpc.DeviceStat), nil
+}
+
+// UpsertOperationTypeStats adds the counts by operation type of stat to the statistics by operation type
+func (m *MemoryStore) UpsertOperationTypeStats(ctx context.Context, stat *DeviceStat) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for operationType, typeStat := range stat.ByType {
+		current, ok := m.types[operationType]
+		if !ok {
+			current = &flaco_grpc.OperationTypeStat{Type: operationType}
+			m.types[operationType] = current
+		}
+		current.Total += typeStat.NbTotalOp
+		current.Successful += typeStat.NbOpSuccess
+		current.Failed += typeStat.NbOpFailed
+	}
+	return nil
+}
+
+// ListOperationTypeStats returns copies of the statistics by operation type, sorted by type
+func (m *MemoryStore) ListOperationTypeStats(ctx context.Context) ([]*flaco_grpc.OperationTypeStat, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make([]*flaco_grpc.OperationTypeStat, 0, len(m.types))
+	for _, stat := range m.types {
+		stats = append(stats, proto.Clone(stat).(*flaco_grpc.OperationTypeStat))
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Type < stats[j].Type })
+	return stats, nil
+}
+
+// FindDeviceStat returns a copy of the statistics of the device
+func (m *MemoryStore) FindDeviceStat(ctx context.Context, deviceName string) (*flaco_grpc.DeviceStat, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stat, ok := m.stats[deviceName]
+	if !ok {
+		return nil, ErrDeviceNotFound
+	}
+	return proto.Clone(stat).(*flaco_grpc.DeviceStat), nil
+}
+
+// ListDeviceStats returns copies of one page of device statistics, sorted by device name
+func (m *MemoryStore) ListDeviceStats(ctx context.Context, nameFilter string, after string, limit int64) ([]*flaco_grpc.DeviceStat, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var names []string
+	for name := range m.stats {
+		if strings.Contains(name, nameFilter) && name > after {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if int64(len(names)) > limit {
+		names = names[:limit]
+	}
+
+	stats := make([]*flaco_grpc.DeviceStat, 0, len(names))
+	for _, name := range names {
+		stats = append(stats, proto.Clone(m.stats[name]).(*flaco_grpc.DeviceStat))
+	}
+	return stats, nil
+}
+
+// FindBatchResult returns a copy of the result recorded for the batch
+func (m *MemoryStore) FindBatchResult(ctx context.Context, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result, ok := m.batches[key]
+	if !ok {
+		return nil, ErrBatchNotFound
+	}
+	return append([]byte(nil), result...), nil
+}
+
+// SaveBatchResult records a copy of the result of the batch
+func (m *MemoryStore) SaveBatchResult(ctx context.Context, key string, result []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.batches[key] = append([]byte(nil), result...)
+	return nil
+}
+
+// WithTransaction calls fn and restores the data as it was before the call if fn fails
+func (m *MemoryStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+
+	// Operations are only ever appended, so remembering the number of operations of each device is enough
+	m.mu.RLock()
+	nbOperations := make(map[string]int, len(m.operations))
+	for name, operations := range m.operations {
+		nbOperations[name] = len(operations)
+	}
+	stats := make(map[string]*flaco_grpc.DeviceStat, len(m.stats))
+	for name, stat := range m.stats {
+		stats[name] = proto.Clone(stat).(*flaco_grpc.DeviceStat)
+	}
+	types := make(map[string]*flaco_grpc.OperationTypeStat, len(m.types))
+	for operationType, stat := range m.types {
+		types[operationType] = proto.Clone(stat).(*flaco_grpc.OperationTypeStat)
+	}
+	m.mu.RUnlock()
+
+	err := fn(ctx)
+	if err != nil {
+		m.mu.Lock()
+		for name, operations := range m.operations {
+			if n, ok := nbOperations[name]; ok {
+				m.operations[name] = operations[:n]
+			} else {
+				delete(m.operations, name)
+			}
+		}
+		m.stats = stats
+		m.types = types
+		m.mu.Unlock()
+	}
+	return err
+}
+
+// Transactional returns true, a failing transaction leaves the data untouched
+func (m *MemoryStore) Transactional() bool {
+	return true
+}
+
+// Close does nothing, the data stays available until the store is garbage collected
+func (m *MemoryStore) Close(ctx context.Context) error {
+	return nil
+}
+
+// Operations returns copies of the operations stored for the device
+func (m *MemoryStore) Operations(deviceName string) []*flaco_grpc.Operation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	operations := make([]*flaco_grpc.Operation, 0, len(m.operations[deviceName]))
+	for _, operation := range m.operations[deviceName] {
+		operations = append(operations, proto.Clone(operation).(*flaco_grpc.Operation))
+	}
+	return operations
+}
@@ -0,0 +1,123 @@
+package serveur
+
+import (
+	"context"
+	"flaco/grpc_and_go/flaco_grpc"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsPath is the path of the HTTP endpoint exposing the metrics in the Prometheus format
+const MetricsPath = "/metrics"
+
+// metricsRegistry holds the metrics of the servers of the process, along with the Go runtime and process metrics
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	// grpcRequests counts the gRPC requests by method and status code
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "flaco_grpc_requests_total",
+		Help: "Number of gRPC requests handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	// grpcDuration measures the time spent handling the gRPC requests, by method
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "flaco_grpc_request_duration_seconds",
+		Help:    "Time spent handling the gRPC requests, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// operationsIngested counts the operations stored, by device and by result of the operation
+	operationsIngested = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "flaco_operations_ingested_total",
+		Help: "Number of operations stored, by device and by result of the operation (succeeded or failed).",
+	}, []string{"device", "result"})
+
+	// operationsRejected counts the operations of the valid devices that could not be stored, by device
+	operationsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "flaco_operations_rejected_total",
+		Help: "Number of operations of the valid devices that could not be stored, by device.",
+	}, []string{"device"})
+
+	// storeWriteDuration measures the writes of the devices to the store, each being one transaction with MongoDB
+	storeWriteDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "flaco_store_write_duration_seconds",
+		Help:    "Time spent writing the operations and statistics of a device to the store (MongoDB).",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// storeWriteErrors counts the writes of the devices to the store that failed
+	storeWriteErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "flaco_store_write_errors_total",
+		Help: "Number of writes of a device to the store (MongoDB) that failed.",
+	})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		grpcRequests, grpcDuration, operationsIngested, operationsRejected, storeWriteDuration, storeWriteErrors,
+	)
+}
+
+// MetricsHandler returns the HTTP handler exposing the metrics in the Prometheus format
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{Registry: metricsRegistry})
+}
+
+// newMetricsServer returns the HTTP server of the metrics endpoint
+func newMetricsServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, MetricsHandler())
+	return &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+}
+
+// observeRequest records a handled gRPC request
+func observeRequest(method string, start time.Time, err error) {
+	grpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	grpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// observeWrite records the write of a device to the store, which lasted since start and failed with err
+func observeWrite(start time.Time, err error) {
+	storeWriteDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		storeWriteErrors.Inc()
+	}
+}
+
+// observeDevice records the operations of a device kept by the store, by result of the operation, and the ones rejected.
+// The store keeps the first accepted operations of the device, some of them even when it failed without a transaction.
+func observeDevice(device *flaco_grpc.Device, result *flaco_grpc.DeviceResult) {
+	kept := GetDeviceStat(&flaco_grpc.Device{Operation: device.GetOperation()[:result.Accepted]})
+	if kept.NbTotalOp > 0 || result.Error == "" {
+		operationsIngested.WithLabelValues(result.DeviceName, "succeeded").Add(float64(kept.NbOpSuccess))
+		operationsIngested.WithLabelValues(result.DeviceName, "failed").Add(float64(kept.NbOpFailed))
+	}
+	if result.Error != "" {
+		operationsRejected.WithLabelValues(result.DeviceName).Add(float64(result.Rejected))
+	}
+}
+
+// metricsUnary measures the unary requests, it runs before any other interceptor so that the rejected ones are measured too
+func metricsUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeRequest(info.FullMethod, start, err)
+	return resp, err
+}
+
+// metricsStream measures the streaming requests, from their start to the end of the stream
+func metricsStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observeRequest(info.FullMethod, start, err)
+	return err
+}
@@ -0,0 +1,169 @@
+package serveur
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollectionMigration reports the migration of a single per-device collection
+type CollectionMigration struct {
+	Device string // Name of the device, which was the name of its collection
+	Moved  int64  // Number of operations copied into the operations collection, the ones copied by a previous run excluded
+}
+
+// deviceDocumentSchema matches the operations of the per-device collections, as written by the previous versions of
+// the server: a type and a state, then optionally a day and a timestamp
+var deviceDocumentSchema = bson.M{
+	"bsonType":             "object",
+	"required":             bson.A{"type", "state"},
+	"additionalProperties": false,
+	"properties": bson.M{
+		"_id":       bson.M{},
+		"type":      bson.M{"bsonType": "string"},
+		"state":     bson.M{"enum": bson.A{"SUCCESS", "FAILED"}},
+		"day":       bson.M{"bsonType": "string"},
+		"timestamp": bson.M{"bsonType": "date"},
+	},
+}
+
+// DeviceCollections lists the collections of the database holding the operations of a single device, as written by
+// the versions of the server storing each device in its own collection. Only the collections whose every document is
+// such an operation are listed, so that the other collections of the database are never migrated.
+func (m *MongoStore) DeviceCollections(ctx context.Context) ([]string, error) {
+	names, err := m.database.ListCollectionNames(ctx, bson.M{"type": "collection"})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var devices []string
+	for _, name := range names {
+		switch {
+		case name == operationCollectionName, name == statCollectionName, name == typeStatCollectionName, name == batchCollectionName:
+		case strings.HasPrefix(name, "system."):
+		default:
+			device, err := m.isDeviceCollection(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("checking collection %s: %w", name, err)
+			}
+			if device {
+				devices = append(devices, name)
+			}
+		}
+	}
+	return devices, nil
+}
+
+// isDeviceCollection tells whether the collection named name has documents, all of them operations of a per-device
+// collection. An empty collection is left alone, nothing tells what it is for.
+func (m *MongoStore) isDeviceCollection(ctx context.Context, name string) (bool, error) {
+	collection := m.database.Collection(name)
+	err := collection.FindOne(ctx, bson.M{}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, wrapError(err)
+	}
+
+	err = collection.FindOne(ctx, bson.M{"$nor": bson.A{bson.M{"$jsonSchema": deviceDocumentSchema}}}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return true, nil // No document of another shape
+	}
+	return false, wrapError(err)
+}
+
+// MigrateDeviceCollections moves the operations of every per-device collection into the operations collection, then
+// drops the per-device collection. The operations keep their _id, so a migration interrupted midway can be run again
+// without duplicating the operations already copied. The statistics are not changed, they already count these operations.
+func (m *MongoStore) MigrateDeviceCollections(ctx context.Context) ([]CollectionMigration, error) {
+	devices, err := m.DeviceCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []CollectionMigration
+	for _, device := range devices {
+		moved, err := m.migrateDeviceCollection(ctx, device)
+		if err != nil {
+			return migrations, fmt.Errorf("migrating collection %s: %w", device, err)
+		}
+		migrations = append(migrations, CollectionMigration{Device: device, Moved: moved})
+	}
+	return migrations, nil
+}
+
+// migrateDeviceCollection copies the operations of a per-device collection in batches of batchSize, then drops it
+func (m *MongoStore) migrateDeviceCollection(ctx context.Context, device string) (int64, error) {
+	source := m.database.Collection(device)
+	target := m.database.Collection(operationCollectionName)
+
+	cursor, err := source.Find(ctx, bson.M{}, options.Find().SetBatchSize(int32(m.batchSize)))
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var moved int64
+	documents := make([]interface{}, 0, m.batchSize)
+	flush := func() error {
+		if len(documents) == 0 {
+			return nil
+		}
+		// Unordered, so that the operations copied by a previous run are skipped instead of stopping the batch
+		_, err := target.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+		copied := int64(len(documents))
+		if err != nil {
+			if !onlyDuplicateKeys(err) {
+				return wrapError(err)
+			}
+			var bulkErr mongo.BulkWriteException
+			errors.As(err, &bulkErr)
+			copied -= int64(len(bulkErr.WriteErrors)) // Each one is an operation copied by a previous run
+		}
+		moved += copied
+		documents = documents[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var document bson.M
+		if err = cursor.Decode(&document); err != nil {
+			return moved, err
+		}
+		document["device"] = device
+		documents = append(documents, document)
+		if len(documents) == m.batchSize {
+			if err = flush(); err != nil {
+				return moved, err
+			}
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		return moved, wrapError(err)
+	}
+	if err = flush(); err != nil {
+		return moved, err
+	}
+
+	return moved, wrapError(source.Drop(ctx))
+}
+
+// onlyDuplicateKeys tells whether every error of a bulk write is a duplicate key error
+func onlyDuplicateKeys(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,404 @@
+package serveur
+
+import (
+	"context"
+	"errors"
+	"flaco/grpc_and_go/config"
+	"flaco/grpc_and_go/flaco_grpc"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const (
+	operationCollectionName = "operations"          // Name of the collection holding the raw operations of every device
+	statCollectionName      = "StatByDevice"        // Name of the collection holding the statistics of each device
+	typeStatCollectionName  = "StatByOperationType" // Name of the collection holding the statistics of each operation type
+	batchCollectionName     = "ProcessedBatches"    // Name of the collection holding the results of the processed batches
+)
+
+// MongoStore is the Store keeping operations and statistics in a MongoDB database.
+// It owns a single pooled client shared by every request.
+type MongoStore struct {
+	client        *mongo.Client   // Pooled connection to the MongoDB instance
+	database      *mongo.Database // Database holding the devices data
+	batchSize     int             // Maximum number of operations written in a single InsertMany
+	transactional bool            // Whether writes are grouped in transactions
+}
+
+// statDocument mirrors a document of the statistics collection
+type statDocument struct {
+	Name       string                   `bson:"name"`            // Device name
+	Total      int64                    `bson:"total"`           // Total number of operations
+	Successful int64                    `bson:"successful"`      // Number of successful operations
+	Failed     int64                    `bson:"failed"`          // Number of failed operations
+	Days       map[string]countDocument `bson:"days,omitempty"`  // Statistics by day
+	Types      map[string]countDocument `bson:"types,omitempty"` // Statistics by operation type
+}
+
+// typeStatDocument mirrors a document of the statistics by operation type collection
+type typeStatDocument struct {
+	Type       string `bson:"type"`       // Operation type
+	Total      int64  `bson:"total"`      // Total number of operations
+	Successful int64  `bson:"successful"` // Number of successful operations
+	Failed     int64  `bson:"failed"`     // Number of failed operations
+}
+
+// countDocument mirrors the statistics of a single day or operation type inside a statistics document
+type countDocument struct {
+	Total      int64 `bson:"total"`      // Total number of operations
+	Successful int64 `bson:"successful"` // Number of successful operations
+	Failed     int64 `bson:"failed"`     // Number of failed operations
+}
+
+// NewMongoStore connects to the configured MongoDB instance and creates a Store writing into the configured database.
+// The instance must be reachable before ctx expires.
+func NewMongoStore(ctx context.Context, cfg config.ServerConfig) (*MongoStore, error) {
+	if cfg.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size %d", cfg.BatchSize)
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoURI))
+	if err != nil {
+		return nil, err // Invalid URI or options
+	}
+	if err = client.Ping(ctx, readpref.Primary()); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("%w: %v", ErrStoreUnavailable, err)
+	}
+
+	store := &MongoStore{client: client, database: client.Database(cfg.Database), batchSize: cfg.BatchSize}
+	if cfg.Transactions != config.TransactionsOff {
+		supported, err := supportsTransactions(ctx, client)
+		if err != nil {
+			client.Disconnect(context.Background())
+			return nil, wrapError(err)
+		}
+		if !supported && cfg.Transactions == config.TransactionsOn {
+			client.Disconnect(context.Background())
+			return nil, errors.New("transactions are required but MongoDB is neither a replica set nor a sharded cluster")
+		}
+		if !supported {
+			println("[LOGS] => Standalone MongoDB, storing without transactions...")
+		}
+		store.transactional = supported
+	}
+
+	// Indexes cannot be created within a transaction, they are created once when the server starts
+	if err = store.createIndexes(ctx); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("creating indexes: %w", wrapError(err))
+	}
+	return store, nil
+}
+
+// createIndexes creates the indexes used to read the operations of a device and to find the statistics,
+// creating an index that already exists does nothing
+func (m *MongoStore) createIndexes(ctx context.Context) error {
+	indexes := map[string][]mongo.IndexModel{
+		operationCollectionName: {
+			{Keys: bson.D{{Key: "device", Value: 1}, {Key: "day", Value: 1}}},
+			{Keys: bson.D{{Key: "device", Value: 1}, {Key: "timestamp", Value: 1}}},
+		},
+		statCollectionName: {
+			{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		typeStatCollectionName: {
+			{Keys: bson.D{{Key: "type", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+	}
+	for collection, models := range indexes {
+		if _, err := m.database.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("%s: %w", collection, err)
+		}
+	}
+	return nil
+}
+
+// supportsTransactions tells whether the MongoDB deployment is a replica set or a sharded cluster,
+// the only deployments supporting transactions
+func supportsTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	var hello bson.M
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false, err
+	}
+	_, replicaSet := hello["setName"]
+	sharded := hello["msg"] == "isdbgrid"
+	return replicaSet || sharded, nil
+}
+
+// Close disconnects the pooled client, waiting for the operations in progress until ctx expires
+func (m *MongoStore) Close(ctx context.Context) error {
+	return m.client.Disconnect(ctx)
+}
+
+// WithTransaction calls fn within a MongoDB transaction, or directly when transactions are not used
+func (m *MongoStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !m.transactional {
+		return fn(ctx)
+	}
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return wrapError(err)
+	}
+	defer session.EndSession(context.Background())
+
+	// The session context carries the transaction to every write fn makes through the store
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	return wrapError(err)
+}
+
+// Transactional tells whether the MongoDB deployment supports transactions and they are enabled
+func (m *MongoStore) Transactional() bool {
+	return m.transactional
+}
+
+// wrapError marks the errors caused by an unreachable MongoDB instance with ErrStoreUnavailable
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var selectionErr topology.ServerSelectionError
+	if errors.As(err, &selectionErr) || mongo.IsNetworkError(err) {
+		return fmt.Errorf("%w: %v", ErrStoreUnavailable, err)
+	}
+	return err
+}
+
+// InsertOperations inserts the operations into the operations collection, in ordered batches of at most
+// batchSize documents so that a device with thousands of operations only costs a few round trips
+func (m *MongoStore) InsertOperations(ctx context.Context, device *flaco_grpc.Device) (int64, error) {
+	coll := m.database.Collection(operationCollectionName)
+	operations := device.Operation
+	opts := options.InsertMany().SetOrdered(true)
+
+	var inserted int64
+	for start := 0; start < len(operations); start += m.batchSize {
+		end := min(start+m.batchSize, len(operations))
+
+		documents := make([]interface{}, 0, end-start)
+		for _, operation := range operations[start:end] {
+			state := "FAILED"
+			if operation.HasSucceeded {
+				state = "SUCCESS"
+			}
+			document := bson.M{
+				"device": device.DeviceName,
+				"type":   operation.Type,
+				"state":  state,
+			}
+			if device.Day != "" {
+				document["day"] = device.Day
+			}
+			if operation.Timestamp != nil {
+				document["timestamp"] = operation.Timestamp.AsTime()
+			}
+			documents = append(documents, document)
+		}
+
+		if _, err := coll.InsertMany(ctx, documents, opts); err != nil {
+			// An ordered batch stops at its first failing document, the ones before it are stored
+			var bulkErr mongo.BulkWriteException
+			if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+				inserted += int64(bulkErr.WriteErrors[0].Index)
+			}
+			return inserted, wrapError(err) // Return an error if insertion fails
+		}
+		inserted += int64(end - start)
+	}
+	return inserted, nil
+}
+
+// UpsertDeviceStat increments the counters of the device in the statistics collection and reads back the new totals
+func (m *MongoStore) UpsertDeviceStat(ctx context.Context, stat *DeviceStat) (*flaco_grpc.DeviceStat, error) {
+	filter := bson.M{"name": stat.DeviceName}
+	increments := bson.M{
+		"total":      stat.NbTotalOp,
+		"successful": stat.NbOpSuccess,
+		"failed":     stat.NbOpFailed,
+	}
+	if stat.Day != "" {
+		// The statistics of the day are kept alongside the all-time totals
+		increments["days."+stat.Day+".total"] = stat.NbTotalOp
+		increments["days."+stat.Day+".successful"] = stat.NbOpSuccess
+		increments["days."+stat.Day+".failed"] = stat.NbOpFailed
+	}
+	for operationType, typeStat := range stat.ByType {
+		increments["types."+operationType+".total"] = typeStat.NbTotalOp
+		increments["types."+operationType+".successful"] = typeStat.NbOpSuccess
+		increments["types."+operationType+".failed"] = typeStat.NbOpFailed
+	}
+	update := bson.M{
+		"$inc": increments,
+		"$setOnInsert": bson.M{
+			"device": stat.DeviceName,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var updated statDocument
+	err := m.database.Collection(statCollectionName).FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return updated.toGRPC(), nil
+}
+
+// UpsertOperationTypeStats increments the counters of each operation type of the device in the statistics by
+// operation type collection, in a single bulk write
+func (m *MongoStore) UpsertOperationTypeStats(ctx context.Context, stat *DeviceStat) error {
+	if len(stat.ByType) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(stat.ByType))
+	for operationType, typeStat := range stat.ByType {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"type": operationType}).
+			SetUpdate(bson.M{"$inc": bson.M{
+				"total":      typeStat.NbTotalOp,
+				"successful": typeStat.NbOpSuccess,
+				"failed":     typeStat.NbOpFailed,
+			}}).
+			SetUpsert(true))
+	}
+	_, err := m.database.Collection(typeStatCollectionName).BulkWrite(ctx, models)
+	return wrapError(err)
+}
+
+// ListOperationTypeStats reads every document of the statistics by operation type collection
+func (m *MongoStore) ListOperationTypeStats(ctx context.Context) ([]*flaco_grpc.OperationTypeStat, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "type", Value: 1}})
+	cursor, err := m.database.Collection(typeStatCollectionName).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	var docs []typeStatDocument
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, wrapError(err)
+	}
+
+	stats := make([]*flaco_grpc.OperationTypeStat, 0, len(docs))
+	for _, doc := range docs {
+		stats = append(stats, &flaco_grpc.OperationTypeStat{
+			Type:       doc.Type,
+			Total:      doc.Total,
+			Successful: doc.Successful,
+			Failed:     doc.Failed,
+		})
+	}
+	return stats, nil
+}
+
+// FindDeviceStat reads the statistics of a single device from the statistics collection
+func (m *MongoStore) FindDeviceStat(ctx context.Context, deviceName string) (*flaco_grpc.DeviceStat, error) {
+	var doc statDocument
+	err := m.database.Collection(statCollectionName).FindOne(ctx, bson.M{"name": deviceName}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrDeviceNotFound
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return doc.toGRPC(), nil
+}
+
+// ListDeviceStats reads one page of device statistics from the statistics collection
+func (m *MongoStore) ListDeviceStats(ctx context.Context, nameFilter string, after string, limit int64) ([]*flaco_grpc.DeviceStat, error) {
+	nameCond := bson.M{}
+	if nameFilter != "" {
+		nameCond["$regex"] = regexp.QuoteMeta(nameFilter)
+	}
+	if after != "" {
+		nameCond["$gt"] = after
+	}
+	filter := bson.M{}
+	if len(nameCond) > 0 {
+		filter["name"] = nameCond
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetLimit(limit)
+
+	cursor, err := m.database.Collection(statCollectionName).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	var docs []statDocument
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, wrapError(err)
+	}
+
+	stats := make([]*flaco_grpc.DeviceStat, 0, len(docs))
+	for _, doc := range docs {
+		stats = append(stats, doc.toGRPC())
+	}
+	return stats, nil
+}
+
+// batchDocument mirrors a document of the processed batches collection
+type batchDocument struct {
+	Key         string    `bson:"_id"`          // Idempotency key of the batch
+	Result      []byte    `bson:"result"`       // Serialized result returned for the batch
+	ProcessedAt time.Time `bson:"processed_at"` // Time the batch was processed
+}
+
+// FindBatchResult reads the result recorded for the batch from the processed batches collection
+func (m *MongoStore) FindBatchResult(ctx context.Context, key string) ([]byte, error) {
+	var doc batchDocument
+	err := m.database.Collection(batchCollectionName).FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrBatchNotFound
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return doc.Result, nil
+}
+
+// SaveBatchResult records the result of the batch in the processed batches collection
+func (m *MongoStore) SaveBatchResult(ctx context.Context, key string, result []byte) error {
+	doc := batchDocument{Key: key, Result: result, ProcessedAt: time.Now()}
+	_, err := m.database.Collection(batchCollectionName).InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil // Another request already recorded the batch
+	}
+	return wrapError(err)
+}
+
+// toGRPC converts a statistics document to the gRPC DeviceStat type, with its days and types sorted
+func (d statDocument) toGRPC() *flaco_grpc.DeviceStat {
+	stat := &flaco_grpc.DeviceStat{
+		DeviceName: d.Name,
+		Total:      d.Total,
+		Successful: d.Successful,
+		Failed:     d.Failed,
+	}
+	for day, counts := range d.Days {
+		stat.Days = append(stat.Days, &flaco_grpc.DayStat{
+			Day:        day,
+			Total:      counts.Total,
+			Successful: counts.Successful,
+			Failed:     counts.Failed,
+		})
+	}
+	sort.Slice(stat.Days, func(i, j int) bool { return stat.Days[i].Day < stat.Days[j].Day })
+	for operationType, counts := range d.Types {
+		stat.Types = append(stat.Types, &flaco_grpc.OperationTypeStat{
+			Type:       operationType,
+			Total:      counts.Total,
+			Successful: counts.Successful,
+			Failed:     counts.Failed,
+		})
+	}
+	sort.Slice(stat.Types, func(i, j int) bool { return stat.Types[i].Type < stat.Types[j].Type })
+	return stat
+}
@@ -0,0 +1,60 @@
+package serveur
+
+import (
+	"context"
+	"errors"
+	"flaco/grpc_and_go/flaco_grpc"
+)
+
+var (
+	// ErrDeviceNotFound is returned by a Store when no statistics exist for the requested device
+	ErrDeviceNotFound = errors.New("device not found")
+
+	// ErrBatchNotFound is returned by a Store when no result has been recorded for the requested batch
+	ErrBatchNotFound = errors.New("batch not found")
+
+	// ErrStoreUnavailable is wrapped by the errors a Store returns when its backend cannot be reached
+	ErrStoreUnavailable = errors.New("store unavailable")
+)
+
+// Store persists the raw operations of the devices and their aggregated statistics
+type Store interface {
+	// InsertOperations stores the raw operations of a device, along with their day, and returns how many were stored
+	InsertOperations(ctx context.Context, device *flaco_grpc.Device) (int64, error)
+
+	// UpsertDeviceStat adds the counts of stat to the all-time statistics of the device, to the ones of each of its
+	// operation types and, when stat has a day, to the ones of that day, creating them if needed, and returns the
+	// updated statistics
+	UpsertDeviceStat(ctx context.Context, stat *DeviceStat) (*flaco_grpc.DeviceStat, error)
+
+	// UpsertOperationTypeStats adds the counts by operation type of stat to the statistics by operation type of
+	// every device, creating them if needed
+	UpsertOperationTypeStats(ctx context.Context, stat *DeviceStat) error
+
+	// ListOperationTypeStats returns the statistics by operation type of every device, sorted by type
+	ListOperationTypeStats(ctx context.Context) ([]*flaco_grpc.OperationTypeStat, error)
+
+	// FindDeviceStat returns the statistics of a device, or ErrDeviceNotFound if the device is unknown
+	FindDeviceStat(ctx context.Context, deviceName string) (*flaco_grpc.DeviceStat, error)
+
+	// ListDeviceStats returns at most limit device statistics sorted by device name.
+	// Only devices whose name contains nameFilter and comes after the given name are returned.
+	ListDeviceStats(ctx context.Context, nameFilter string, after string, limit int64) ([]*flaco_grpc.DeviceStat, error)
+
+	// FindBatchResult returns the result recorded for an already processed batch, or ErrBatchNotFound
+	FindBatchResult(ctx context.Context, key string) ([]byte, error)
+
+	// SaveBatchResult records the result of a processed batch so that replays of the batch can return it
+	SaveBatchResult(ctx context.Context, key string, result []byte) error
+
+	// WithTransaction calls fn so that the writes it makes through the store are committed together or not at all.
+	// fn may be called again when the transaction hits a transient error. Stores that cannot provide transactions
+	// call fn directly and report it through Transactional.
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// Transactional tells whether WithTransaction rolls back the writes of a failing function
+	Transactional() bool
+
+	// Close releases the resources held by the store
+	Close(ctx context.Context) error
+}
@@ -0,0 +1,200 @@
+package serveur
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"flaco/grpc_and_go/config"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+)
+
+// bearerPrefix prefixes the token in the authorization metadata of the requests
+const bearerPrefix = "Bearer "
+
+// APIKey is a static bearer token, as listed in the API keys file
+type APIKey struct {
+	Name    string   `yaml:"name"`    // Name of the client holding the key
+	Key     string   `yaml:"key"`     // Secret value of the key
+	Devices []string `yaml:"devices"` // Prefixes of the names of the devices the key may upload, any device when empty
+	Roles   []string `yaml:"roles"`   // Roles of the key, required unless legacy_admin_roles makes the keys without roles admins
+
+	roles []Role // Checked roles
+}
+
+// TokenClaims are the claims of the JWTs accepted as bearer tokens, the subject naming the client
+type TokenClaims struct {
+	Devices []string `json:"devices,omitempty"` // Prefixes of the names of the devices the token may upload, any device when empty
+	Roles   []string `json:"roles,omitempty"`   // Roles of the token, required unless legacy_admin_roles makes the tokens without roles admins
+	jwt.RegisteredClaims
+}
+
+// IssueToken returns a JWT signed with secret for the named client, allowed to upload the devices whose name starts
+// with one of prefixes, any device when empty, with roles, at least one, and expiring after ttl
+func IssueToken(secret []byte, name string, prefixes []string, roles []Role, ttl time.Duration) (string, error) {
+	if len(roles) == 0 {
+		return "", errors.New("a token needs at least one role")
+	}
+	for _, role := range roles {
+		if _, err := ParseRole(string(role)); err != nil {
+			return "", err
+		}
+	}
+	now := time.Now()
+	claims := TokenClaims{
+		Devices: prefixes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   name,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	for _, role := range roles {
+		claims.Roles = append(claims.Roles, string(role))
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// tokenAuthenticator identifies the clients by the bearer token of their requests, an API key or a JWT
+type tokenAuthenticator struct {
+	keys   map[[sha256.Size]byte]APIKey // API keys by hash of their value
+	secret []byte                       // HMAC secret of the JWTs, JWTs are rejected when nil
+
+	legacyAdmin bool // The JWTs without roles are admins
+}
+
+// loadTokenAuthenticator reads the API keys and the JWT secret of the configuration
+func loadTokenAuthenticator(cfg config.ServerConfig) (*tokenAuthenticator, error) {
+	t := &tokenAuthenticator{keys: map[[sha256.Size]byte]APIKey{}, legacyAdmin: cfg.LegacyAdminRoles}
+	if cfg.APIKeysFile != "" {
+		keys, err := loadAPIKeys(cfg.APIKeysFile, cfg.LegacyAdminRoles)
+		if err != nil {
+			return nil, fmt.Errorf("loading API keys: %w", err)
+		}
+		for _, key := range keys {
+			t.keys[sha256.Sum256([]byte(key.Key))] = key
+		}
+	}
+	if cfg.JWTSecretFile != "" {
+		secret, err := ReadJWTSecret(cfg.JWTSecretFile)
+		if err != nil {
+			return nil, err
+		}
+		t.secret = secret
+	}
+	return t, nil
+}
+
+// ReadJWTSecret reads the HMAC secret of the JWTs from the file at path, ignoring the surrounding blanks
+func ReadJWTSecret(path string) ([]byte, error) {
+	secret, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading JWT secret: %w", err)
+	}
+	if secret = bytes.TrimSpace(secret); len(secret) == 0 {
+		return nil, errors.New("loading JWT secret: " + path + " is empty")
+	}
+	return secret, nil
+}
+
+// loadAPIKeys reads the API keys of the YAML file at path, the keys without roles being admins with legacyAdmin
+func loadAPIKeys(path string, legacyAdmin bool) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []APIKey
+	if err = yaml.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	names := map[string]bool{}
+	for i, key := range keys {
+		if keys[i].roles, err = parseRoles(key.Roles, legacyAdmin); err != nil {
+			return nil, fmt.Errorf("%s: key %s: %w", path, key.Name, err)
+		}
+		switch {
+		case key.Name == "":
+			return nil, fmt.Errorf("%s: key %d has no name", path, i)
+		case key.Key == "":
+			return nil, fmt.Errorf("%s: key %s has no value", path, key.Name)
+		case names[key.Name]:
+			return nil, fmt.Errorf("%s: key %s is listed twice", path, key.Name)
+		}
+		names[key.Name] = true
+	}
+	return keys, nil
+}
+
+// identify adds the identity of the bearer token of the request to ctx
+func (t *tokenAuthenticator) identify(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, healthServicePrefix) {
+		return ctx, nil
+	}
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, ok := t.apiKey(token); ok {
+		return withIdentity(ctx, &Identity{Name: key.Name, DevicePrefixes: devicePrefixes(key.Devices), Roles: key.roles}), nil
+	}
+	if t.secret == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	claims := &TokenClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return t.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	if claims.Subject == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid token: no subject")
+	}
+	roles, err := parseRoles(claims.Roles, t.legacyAdmin)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	return withIdentity(ctx, &Identity{Name: claims.Subject, DevicePrefixes: devicePrefixes(claims.Devices), Roles: roles}), nil
+}
+
+// apiKey returns the API key whose value is token. Keys are looked up by hash then compared in constant time, so that
+// the lookup does not leak the value of the keys.
+func (t *tokenAuthenticator) apiKey(token string) (APIKey, bool) {
+	key, ok := t.keys[sha256.Sum256([]byte(token))]
+	if !ok || subtle.ConstantTimeCompare([]byte(key.Key), []byte(token)) != 1 {
+		return APIKey{}, false
+	}
+	return key, true
+}
+
+// bearerToken returns the bearer token of the authorization metadata of the request
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", status.Error(codes.Unauthenticated, "bearer token required")
+	}
+	if len(values) > 1 || !strings.HasPrefix(values[0], bearerPrefix) {
+		return "", status.Error(codes.Unauthenticated, "malformed authorization, expected a single bearer token")
+	}
+	return strings.TrimPrefix(values[0], bearerPrefix), nil
+}
+
+// devicePrefixes returns the prefixes of the devices a token may upload, the empty prefix allowing any device when the
+// token lists none
+func devicePrefixes(devices []string) []string {
+	if len(devices) == 0 {
+		return []string{""}
+	}
+	return devices
+}
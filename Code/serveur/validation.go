@@ -0,0 +1,129 @@
+package serveur
+
+import (
+	"flaco/grpc_and_go/config"
+	"flaco/grpc_and_go/flaco_grpc"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxViolations is the maximum number of violations reported for a single request, so that a request full of
+// invalid operations does not produce an error larger than the request itself
+const maxViolations = 100
+
+// devicePattern matches the accepted device names: 1 to 64 letters, digits, dashes and underscores
+var devicePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Validator checks the incoming devices before anything is stored
+type Validator struct {
+	allowedTypes  map[string]bool // Accepted operation types, any type is accepted when empty
+	maxOperations int             // Maximum number of operations of a single device
+}
+
+// NewValidator creates a Validator enforcing the limits of the server configuration
+func NewValidator(cfg config.ServerConfig) *Validator {
+	v := &Validator{allowedTypes: make(map[string]bool, len(cfg.AllowedTypes)), maxOperations: cfg.MaxOperations}
+	for _, operationType := range cfg.AllowedTypes {
+		v.allowedTypes[operationType] = true
+	}
+	return v
+}
+
+// violations collects the field violations found in a request
+type violations []*errdetails.BadRequest_FieldViolation
+
+// add records a violation of field, unless maxViolations have already been recorded
+func (v *violations) add(field, format string, args ...any) {
+	if len(*v) < maxViolations {
+		*v = append(*v, &errdetails.BadRequest_FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+}
+
+// err returns nil when no violation was found, or an InvalidArgument status error detailing every violation
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(v))
+	for _, violation := range v {
+		messages = append(messages, violation.Field+": "+violation.Description)
+	}
+	st := status.New(codes.InvalidArgument, "invalid request: "+strings.Join(messages, "; "))
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: v}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// ValidateRequest checks every device of the request, along with the day of the request and the uniqueness of each
+// device and day pair, and returns an InvalidArgument status error with a BadRequest detail when the request is invalid
+func (v *Validator) ValidateRequest(req *flaco_grpc.Request) error {
+	if req == nil {
+		return status.Error(codes.InvalidArgument, "nil request")
+	}
+
+	var found violations
+	if !keyPattern.MatchString(req.GetDay()) {
+		found.add("day", "%q must only contain letters, digits, dashes and underscores", req.GetDay())
+	}
+	seen := make(map[string]int) // Index of the first device of each device and day pair
+	for i, device := range req.GetDevice() {
+		field := fmt.Sprintf("device[%d]", i)
+		v.checkDevice(&found, field, device)
+
+		day := device.GetDay()
+		if day == "" {
+			day = req.GetDay()
+		}
+		pair := device.GetDeviceName() + "\x00" + day
+		if first, ok := seen[pair]; ok {
+			found.add(field+".device_name", "duplicate of device[%d], a device must appear once per day", first)
+		} else {
+			seen[pair] = i
+		}
+	}
+	return found.err()
+}
+
+// ValidateDevice checks a single device, its fields being reported under the given field path
+func (v *Validator) ValidateDevice(field string, device *flaco_grpc.Device) error {
+	var found violations
+	v.checkDevice(&found, field, device)
+	return found.err()
+}
+
+// checkDevice records the violations of a single device
+func (v *Validator) checkDevice(found *violations, field string, device *flaco_grpc.Device) {
+	if device == nil {
+		found.add(field, "must not be null")
+		return
+	}
+	if device.GetDeviceName() == "" {
+		found.add(field+".device_name", "must not be empty")
+	} else if !devicePattern.MatchString(device.GetDeviceName()) {
+		found.add(field+".device_name", "%q must be 1 to 64 letters, digits, dashes and underscores", device.GetDeviceName())
+	}
+	if !keyPattern.MatchString(device.GetDay()) {
+		found.add(field+".day", "%q must only contain letters, digits, dashes and underscores", device.GetDay())
+	}
+	if n := len(device.GetOperation()); n > v.maxOperations {
+		found.add(field+".operation", "%d operations, at most %d are accepted per device", n, v.maxOperations)
+		return // Checking every operation of a device that is rejected anyway is not worth it
+	}
+	for j, operation := range device.GetOperation() {
+		operationField := fmt.Sprintf("%s.operation[%d].type", field, j)
+		switch {
+		case operation.GetType() == "":
+			found.add(operationField, "must not be empty")
+		case len(v.allowedTypes) > 0 && !v.allowedTypes[operation.GetType()]:
+			found.add(operationField, "%q is not an accepted operation type", operation.GetType())
+		case !keyPattern.MatchString(operation.GetType()):
+			found.add(operationField, "%q must only contain letters, digits, dashes and underscores", operation.GetType())
+		}
+	}
+}